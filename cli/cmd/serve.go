@@ -3,6 +3,9 @@ package cmd
 import (
 	"os"
 
+	purge "movies/internal/purge"
+	gdpr "movies/internal/user/gdpr"
+	config "movies/utils/config"
 	logger "movies/utils/logger"
 	server "movies/utils/server"
 
@@ -18,6 +21,15 @@ func Serve() *cobra.Command {
 
 	// Runner
 	cmd.Run = func(cmd *cobra.Command, args []string) {
+		// Scheduled purge of soft-deleted rows
+		if interval := config.Purge().Interval(); interval > 0 {
+			go purge.Schedule(cmd.Context(), interval)
+		}
+		// Scheduled erasure of deleted accounts
+		if interval := config.Account().EraseInterval(); interval > 0 {
+			go gdpr.Schedule(cmd.Context(), interval)
+		}
+
 		logger.Info(cmd.Context(), "Launch server at :%s", os.Getenv("PORT"))
 		if err := server.Start(); err != nil {
 			logger.Error(cmd.Context(), err.Error())
@@ -22,6 +22,7 @@ func Command() *cobra.Command {
 	// SubCommand
 	cmd.AddCommand(Init())
 	cmd.AddCommand(Reset())
+	cmd.AddCommand(Purge())
 
 	return cmd
 }
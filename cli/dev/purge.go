@@ -0,0 +1,47 @@
+package dev
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	purge "movies/internal/purge"
+
+	lo "github.com/samber/lo"
+	cobra "github.com/spf13/cobra"
+	viper "github.com/spf13/viper"
+)
+
+func Purge() *cobra.Command {
+	// Command
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Hard-delete soft-deleted rows past their retention",
+	}
+
+	// Flags
+	cmd.Flags().String("older-than", "90d", "default retention, overridden per table by PURGE_RETENTION_<TABLE>")
+	cmd.Flags().Bool("dry-run", false, "report what would be removed")
+	lo.Must0(viper.BindPFlag("Purge_OLDER_THAN", cmd.Flags().Lookup("older-than")))
+
+	// Runner
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		dryRun := lo.Must(cmd.Flags().GetBool("dry-run"))
+
+		reports, err := purge.Run(ctx, dryRun)
+		if err != nil {
+			panic(err)
+		}
+
+		// Print report
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TABLE\tRETENTION\tCUTOFF\t"+lo.Ternary(dryRun, "TO REMOVE", "REMOVED"))
+		for _, report := range reports {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", report.Table, report.Retention, report.Cutoff.Format("2006-01-02 15:04"), report.Count)
+		}
+		lo.Must0(w.Flush())
+	}
+
+	return cmd
+}
@@ -0,0 +1,189 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	config "movies/utils/config"
+	pg "movies/utils/pg"
+
+	errors "emperror.dev/errors"
+)
+
+var encoding = base64.RawURLEncoding
+
+// Algorithm of every token, Ed25519 signatures
+const algorithm = "EdDSA"
+
+var ErrInvalid = errors.New("invalid token")
+
+/*============================================================================*/
+/*=====*                              Keys                              *=====*/
+/*============================================================================*/
+
+type keySet struct {
+	active string
+	keys   map[string]ed25519.PrivateKey
+}
+
+var (
+	keys     *keySet
+	keysOnce sync.Once
+)
+
+// keyring: Signing keys loaded from configuration
+//
+// Outside of online environments, a key is generated when none is configured.
+func keyring() *keySet {
+	keysOnce.Do(func() {
+		keys = &keySet{active: config.Auth().JWTKid(), keys: map[string]ed25519.PrivateKey{}}
+		for kid, seed := range config.Auth().JWTKeys() {
+			keys.keys[kid] = ed25519.NewKeyFromSeed(seed)
+		}
+
+		if len(keys.keys) == 0 && !config.IsOnline() {
+			_, key, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				log.Fatal(err)
+			}
+			keys.active = "development"
+			keys.keys[keys.active] = key
+		}
+		if _, ok := keys.keys[keys.active]; !ok {
+			log.Fatalf("JWT key `%s` is not configured", keys.active)
+		}
+	})
+	return keys
+}
+
+/*============================================================================*/
+/*=====*                             Claims                             *=====*/
+/*============================================================================*/
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid"`
+}
+
+// Claims: Registered claims of an access token
+type Claims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	ID        string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// NewClaims: Claims of an access token for a subject
+func NewClaims(subject string) Claims {
+	now := time.Now()
+	return Claims{
+		Issuer:    config.Auth().JWTIssuer(),
+		Subject:   subject,
+		ID:        pg.EncodeShortUUID(pg.NewUUID()),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(config.Auth().AccessTTL()).Unix(),
+	}
+}
+
+/*============================================================================*/
+/*=====*                              API                               *=====*/
+/*============================================================================*/
+
+// Sign: Encode and sign claims with the active key
+func Sign(claims Claims) (string, error) {
+	ring := keyring()
+
+	h, err := json.Marshal(header{Alg: algorithm, Typ: "JWT", Kid: ring.active})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	payload := encoding.EncodeToString(h) + "." + encoding.EncodeToString(c)
+	signature := ed25519.Sign(ring.keys[ring.active], []byte(payload))
+	return payload + "." + encoding.EncodeToString(signature), nil
+}
+
+// Verify: Check the signature, issuer and expiry of a token
+func Verify(token string) (Claims, error) {
+	var claims Claims
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, ErrInvalid
+	}
+
+	var h header
+	if err := decodePart(parts[0], &h); err != nil || h.Alg != algorithm {
+		return claims, ErrInvalid
+	}
+	key, ok := keyring().keys[h.Kid]
+	if !ok {
+		return claims, ErrInvalid
+	}
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil || !ed25519.Verify(key.Public().(ed25519.PublicKey), []byte(parts[0]+"."+parts[1]), signature) {
+		return claims, ErrInvalid
+	}
+
+	if err := decodePart(parts[1], &claims); err != nil {
+		return claims, ErrInvalid
+	} else if claims.Issuer != config.Auth().JWTIssuer() || time.Now().Unix() >= claims.ExpiresAt {
+		return claims, ErrInvalid
+	}
+	return claims, nil
+}
+
+// JWK: Public key in the JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+// JWKS: Public keys of every configured key, active or retired
+func JWKS() map[string][]JWK {
+	ring := keyring()
+
+	kids := make([]string, 0, len(ring.keys))
+	for kid := range ring.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	result := make([]JWK, 0, len(kids))
+	for _, kid := range kids {
+		result = append(result, JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   encoding.EncodeToString(ring.keys[kid].Public().(ed25519.PublicKey)),
+			Kid: kid,
+			Use: "sig",
+			Alg: algorithm,
+		})
+	}
+	return map[string][]JWK{"keys": result}
+}
+
+func decodePart(part string, dst any) error {
+	raw, err := encoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
@@ -0,0 +1,104 @@
+package mail
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	user "movies/internal/user/model"
+	config "movies/utils/config"
+	mailer "movies/utils/mailer"
+)
+
+//go:embed templates
+var files embed.FS
+
+const (
+	verifyEmail   = "verify_email"
+	resetPassword = "reset_password"
+)
+
+var (
+	templates     *mailer.Templates
+	templatesOnce sync.Once
+)
+
+func load() *mailer.Templates {
+	templatesOnce.Do(func() {
+		fsys, err := fs.Sub(files, "templates")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if templates, err = mailer.NewTemplates(fsys, verifyEmail, resetPassword); err != nil {
+			log.Fatal(err)
+		}
+	})
+	return templates
+}
+
+type data struct {
+	Username string
+	URL      string
+	Expires  lifetime
+}
+
+// lifetime: Duration as a count of `day`, `hour` or `minute`
+type lifetime struct {
+	Count int
+	Unit  string
+}
+
+// SendVerification: Email the link confirming the address of a user
+func SendVerification(ctx context.Context, u *user.User, token string) error {
+	return send(ctx, u, verifyEmail, "/verify-email", token, config.Auth().VerificationTTL())
+}
+
+// SendPasswordReset: Email the link resetting the password of a user
+func SendPasswordReset(ctx context.Context, u *user.User, token string) error {
+	return send(ctx, u, resetPassword, "/reset-password", token, config.Auth().PasswordResetTTL())
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+func send(ctx context.Context, u *user.User, name, path, token string, ttl time.Duration) error {
+	locale, err := user.SettingLanguage.Get(u.Setting)
+	if err != nil {
+		return err
+	}
+
+	link := config.Mail().BaseURL() + path + "?" + url.Values{"token": {token}}.Encode()
+	msg, err := load().Render(name, locale, u.Email.String, data{
+		Username: u.Username,
+		URL:      link,
+		Expires:  duration(ttl),
+	})
+	if err != nil {
+		return err
+	}
+	return mailer.Send(ctx, msg)
+}
+
+// duration: Lifetime of a link in its largest whole unit, worded by the
+// `duration` block of each locale
+func duration(ttl time.Duration) lifetime {
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{24 * time.Hour, "day"},
+		{time.Hour, "hour"},
+		{time.Minute, "minute"},
+	}
+	for _, unit := range units {
+		if ttl >= unit.size || unit.size == time.Minute {
+			return lifetime{Count: int(ttl / unit.size), Unit: unit.name}
+		}
+	}
+	return lifetime{}
+}
@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	jwt "movies/internal/auth/jwt"
+	pat "movies/internal/auth/pat"
+	session "movies/internal/auth/session"
+	oauth "movies/internal/oauth/server"
+	rbac "movies/internal/rbac/model"
+	user "movies/internal/user/model"
+	cerrors "movies/utils/cerrors"
+	pg "movies/utils/pg"
+	principal "movies/utils/principal"
+	render "movies/utils/render"
+	sql "movies/utils/sql"
+
+	mux "github.com/gorilla/mux"
+	pgtype "github.com/jackc/pgtype"
+)
+
+/*============================================================================*/
+/*=====*                             Context                            *=====*/
+/*============================================================================*/
+
+type ctxKey string
+
+const userCtxKey ctxKey = "user"
+
+// CurrentUser: User authenticated for a request
+func CurrentUser(ctx context.Context) (*user.User, bool) {
+	u, ok := ctx.Value(userCtxKey).(*user.User)
+	return u, ok
+}
+
+// Login: Attach an authenticated user and their grants to a context
+func Login(ctx context.Context, u *user.User) (context.Context, error) {
+	roles, permissions, err := rbac.Grants(ctx, pg.EmptyTx(), u.ID)
+	if err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, userCtxKey, u)
+	return principal.With(ctx, principal.Principal{
+		UserID:      u.ID,
+		Roles:       roles,
+		Permissions: permissions,
+	}), nil
+}
+
+/*============================================================================*/
+/*=====*                           Middleware                           *=====*/
+/*============================================================================*/
+
+// Authenticate: Load the authenticated user into the request context
+//
+// A bearer token, either a JWT access token, a personal access token or an
+// OAuth access token, takes precedence over the session cookie. Requests without credentials go
+// through anonymously, invalid bearer tokens are rejected.
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var userID pgtype.UUID
+		var scopes []string
+		if bearer, ok := Bearer(r); ok && pat.Is(bearer) {
+			token, err := pat.Authenticate(ctx, pg.EmptyTx(), bearer, session.ClientIP(r))
+			if errors.Is(err, pat.ErrInvalid) {
+				render.Status(w, r, http.StatusUnauthorized, cerrors.NewString("Invalid access token"))
+				return
+			} else if err != nil {
+				render.Error(w, r, err)
+				return
+			}
+			userID, scopes = token.UserID, token.Scopes
+			if scopes == nil {
+				scopes = []string{}
+			}
+		} else if ok && oauth.Is(bearer) {
+			token, err := oauth.Authenticate(ctx, pg.EmptyTx(), bearer)
+			if errors.Is(err, oauth.ErrInvalid) {
+				render.Status(w, r, http.StatusUnauthorized, cerrors.NewString("Invalid access token"))
+				return
+			} else if err != nil {
+				render.Error(w, r, err)
+				return
+			}
+			userID, scopes = token.UserID, token.Scopes
+			if scopes == nil {
+				scopes = []string{}
+			}
+		} else if ok {
+			claims, err := jwt.Verify(bearer)
+			if err != nil {
+				render.Status(w, r, http.StatusUnauthorized, cerrors.NewString("Invalid access token"))
+				return
+			}
+			if userID, err = pg.ParseUUID(claims.Subject); err != nil {
+				render.Status(w, r, http.StatusUnauthorized, cerrors.NewString("Invalid access token"))
+				return
+			}
+		} else if plain, ok := session.Cookie(r); ok {
+			s, err := session.Find(ctx, pg.EmptyTx(), plain)
+			if err != nil && !pg.IsNotFound(err) {
+				render.Error(w, r, err)
+				return
+			} else if err == nil {
+				userID = s.UserID
+			}
+		}
+
+		if userID.Status == pgtype.Present {
+			u, err := findUser(ctx, userID)
+			if err != nil && !pg.IsNotFound(err) {
+				render.Error(w, r, err)
+				return
+			} else if err == nil {
+				if ctx, err = Login(ctx, u); err != nil {
+					render.Error(w, r, err)
+					return
+				}
+				if scopes != nil {
+					p, _ := principal.FromContext(ctx)
+					p.Scopes = scopes
+					ctx = principal.With(ctx, p)
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Require: Reject requests whose principal lacks a permission
+//
+//	s.Use(middleware.Require(rbac.PermTrashRestore))
+func Require(permission string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if Can(w, r, permission) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// Can: Check a permission inside a handler, writing the 401 or 403 response
+// when it is missing
+//
+//	if !middleware.Can(w, r, rbac.PermMovieWrite) {
+//		return
+//	}
+func Can(w http.ResponseWriter, r *http.Request, permission string) bool {
+	p, ok := principal.FromContext(r.Context())
+	if !ok {
+		render.Unauthorized(w, r)
+		return false
+	} else if !p.Can(permission) {
+		render.Forbidden(w, r)
+		return false
+	}
+	return true
+}
+
+// RequireScope: Reject personal access tokens lacking a scope
+func RequireScope(scope string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if HasScope(w, r, scope) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// HasScope: Check a scope inside a handler, writing the 401 or 403 response
+// when it is missing
+func HasScope(w http.ResponseWriter, r *http.Request, scope string) bool {
+	p, ok := principal.FromContext(r.Context())
+	if !ok {
+		render.Unauthorized(w, r)
+		return false
+	} else if !p.HasScope(scope) {
+		render.Status(w, r, http.StatusForbidden, cerrors.NewString("Token lacks the `%s` scope", scope))
+		return false
+	}
+	return true
+}
+
+// Unscoped: Reject personal access tokens, for endpoints managing credentials
+func Unscoped(w http.ResponseWriter, r *http.Request) bool {
+	p, ok := principal.FromContext(r.Context())
+	if !ok {
+		render.Unauthorized(w, r)
+		return false
+	} else if p.Scoped() {
+		render.Status(w, r, http.StatusForbidden, cerrors.NewString("Personal access tokens cannot manage credentials"))
+		return false
+	}
+	return true
+}
+
+// Bearer: Token of the `Authorization: Bearer` header
+func Bearer(r *http.Request) (string, bool) {
+	scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(value) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(value), true
+}
+
+func findUser(ctx context.Context, id pgtype.UUID) (*user.User, error) {
+	return sql.Read[user.User]().Where(sql.I("id").Eq(id)).FindOne(ctx, pg.EmptyTx())
+}
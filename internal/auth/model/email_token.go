@@ -0,0 +1,46 @@
+package model
+
+import (
+	pgtype "github.com/jackc/pgtype"
+)
+
+// Purpose: What an email token allows
+type Purpose string
+
+const (
+	PurposeVerifyEmail   Purpose = "verify_email"
+	PurposeResetPassword Purpose = "reset_password"
+)
+
+// EmailToken: Signed single-use token sent by email
+//
+// The email is recorded so a token does not outlive a change of address.
+type EmailToken struct {
+	ID        pgtype.UUID        `json:"id" db:"id"`
+	UserID    pgtype.UUID        `json:"user_id" db:"user_id"`
+	Purpose   Purpose            `json:"purpose" db:"purpose"`
+	Email     string             `json:"email" db:"email"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at" db:"expires_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at" db:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at" db:"created_at"`
+}
+
+func (EmailToken) TableName() string { return "email_tokens" }
+
+func (obj EmailToken) GetPK() pgtype.UUID { return obj.ID }
+
+// EmailTokenBody: Body redeeming an email token
+type EmailTokenBody struct {
+	Token string `json:"token" validate:"required,max=512"`
+}
+
+// ForgotPassword: Body of a password reset request
+type ForgotPassword struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPassword: Body of a password reset
+type ResetPassword struct {
+	Token    string `json:"token" validate:"required,max=512"`
+	Password string `json:"password" validate:"required,min=10,max=256"`
+}
@@ -0,0 +1,16 @@
+package model
+
+// Register: Body of a sign up
+type Register struct {
+	Username string `json:"username" validate:"required,alphanumdot,max=64"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=10,max=256"`
+}
+
+// Login: Body of a sign in
+type Login struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,max=256"`
+	// TOTP or recovery code, when two-factor is enabled
+	Code string `json:"code" validate:"omitempty,max=32"`
+}
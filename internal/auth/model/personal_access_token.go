@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	pgtype "github.com/jackc/pgtype"
+)
+
+// Scopes a personal access token can be limited to
+const (
+	ScopeCatalogRead      = "catalog:read"
+	ScopeCatalogWrite     = "catalog:write"
+	ScopeSuggestionsWrite = "suggestions:write"
+	ScopeProfileRead      = "profile:read"
+	ScopeProfileWrite     = "profile:write"
+	ScopeListsRead        = "lists:read"
+	ScopeListsWrite       = "lists:write"
+	ScopeAdmin            = "admin"
+)
+
+// PersonalAccessToken: Long-lived API token of a user, limited to scopes
+type PersonalAccessToken struct {
+	ID         pgtype.UUID        `json:"id" db:"id"`
+	UserID     pgtype.UUID        `json:"user_id" db:"user_id"`
+	Name       string             `json:"name" db:"name"`
+	TokenHash  []byte             `json:"-" db:"token_hash"`
+	Scopes     []string           `json:"scopes" db:"scopes"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at" db:"expires_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at" db:"last_used_at"`
+	LastUsedIP pgtype.Text        `json:"last_used_ip" db:"last_used_ip"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at" db:"created_at"`
+}
+
+func (PersonalAccessToken) TableName() string { return "personal_access_tokens" }
+
+func (obj PersonalAccessToken) GetPK() pgtype.UUID { return obj.ID }
+
+// CreatedToken: New token with its plaintext, only shown once
+type CreatedToken struct {
+	*PersonalAccessToken
+	Token string `json:"token"`
+}
+
+// CreateToken: Body of a personal access token creation
+type CreateToken struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,unique,dive,oneof=catalog:read catalog:write suggestions:write profile:read profile:write lists:read lists:write admin"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	pgtype "github.com/jackc/pgtype"
+)
+
+// RecoveryCode: Single-use code replacing a TOTP code
+type RecoveryCode struct {
+	ID        pgtype.UUID        `json:"id" db:"id"`
+	UserID    pgtype.UUID        `json:"user_id" db:"user_id"`
+	CodeHash  []byte             `json:"-" db:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at" db:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at" db:"created_at"`
+}
+
+func (RecoveryCode) TableName() string { return "recovery_codes" }
+
+func (obj RecoveryCode) GetPK() pgtype.UUID { return obj.ID }
+
+// Enrolment: Secret of a pending TOTP enrolment
+type Enrolment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RecoveryCodes: Plaintext recovery codes, only shown once
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactor: Body confirming a two-factor operation
+type TwoFactor struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	pgtype "github.com/jackc/pgtype"
+)
+
+// RefreshToken: Single-use token, rotated on every use within its family
+type RefreshToken struct {
+	ID        pgtype.UUID        `json:"id" db:"id"`
+	UserID    pgtype.UUID        `json:"user_id" db:"user_id"`
+	FamilyID  pgtype.UUID        `json:"family_id" db:"family_id"`
+	ParentID  pgtype.UUID        `json:"parent_id" db:"parent_id"`
+	TokenHash []byte             `json:"-" db:"token_hash"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at" db:"expires_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at" db:"used_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at" db:"revoked_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at" db:"created_at"`
+}
+
+func (RefreshToken) TableName() string { return "refresh_tokens" }
+
+func (obj RefreshToken) GetPK() pgtype.UUID { return obj.ID }
+
+// TokenPair: Tokens issued to a client
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// Refresh: Body of a token refresh or revocation
+type Refresh struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
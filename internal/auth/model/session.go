@@ -0,0 +1,19 @@
+package model
+
+import (
+	pgtype "github.com/jackc/pgtype"
+)
+
+type Session struct {
+	ID        pgtype.UUID        `json:"id" db:"id"`
+	UserID    pgtype.UUID        `json:"user_id" db:"user_id"`
+	TokenHash []byte             `json:"-" db:"token_hash"`
+	IP        string             `json:"ip" db:"ip"`
+	UserAgent string             `json:"user_agent" db:"user_agent"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at" db:"expires_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at" db:"created_at"`
+}
+
+func (Session) TableName() string { return "sessions" }
+
+func (obj Session) GetPK() pgtype.UUID { return obj.ID }
@@ -0,0 +1,166 @@
+package onetime
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	model "movies/internal/auth/model"
+	user "movies/internal/user/model"
+	config "movies/utils/config"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	errors "emperror.dev/errors"
+	pgtype "github.com/jackc/pgtype"
+)
+
+// Signed single-use tokens sent by email, as `payload.signature`.
+//
+// The payload carries the purpose, the row ID and the expiry so forged or
+// expired tokens are rejected without a query. The row makes them single-use.
+
+var encoding = base64.RawURLEncoding
+
+var ErrInvalid = errors.New("invalid or expired token")
+
+var (
+	secret     []byte
+	secretOnce sync.Once
+)
+
+// key: HMAC key from configuration
+//
+// Outside of online environments, a key is generated when none is configured.
+func key() []byte {
+	secretOnce.Do(func() {
+		secret = []byte(config.Auth().TokenSecret())
+		if len(secret) == 0 && !config.IsOnline() {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if len(secret) < 32 {
+			log.Fatal("AUTH_TOKEN_SECRET must be at least 32 bytes long")
+		}
+	})
+	return secret
+}
+
+/*============================================================================*/
+/*=====*                              API                               *=====*/
+/*============================================================================*/
+
+// Issue: Create a token for the current email of a user
+func Issue(ctx context.Context, tx pg.Tx, u *user.User, purpose model.Purpose, ttl time.Duration) (string, error) {
+	if u.Email.Status != pgtype.Present {
+		return "", errors.New("user has no email")
+	}
+
+	token := &model.EmailToken{}
+	if err := sql.Create(ctx, tx, token, sql.Record{
+		"user_id":    u.ID,
+		"purpose":    purpose,
+		"email":      u.Email.String,
+		"expires_at": time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+
+	payload := strings.Join([]string{
+		string(purpose),
+		pg.FormatUUID(token.ID),
+		strconv.FormatInt(token.ExpiresAt.Time.Unix(), 10),
+	}, ".")
+	return encoding.EncodeToString([]byte(payload)) + "." + encoding.EncodeToString(sign(payload)), nil
+}
+
+// Consume: Redeem a token once, while it is live and the email unchanged
+func Consume(ctx context.Context, tx pg.Tx, plain string, purpose model.Purpose) (*model.EmailToken, error) {
+	id, err := verify(plain, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &model.EmailToken{}
+	err = sql.Update(ctx, tx, token, false, sql.Record{"used_at": sql.NOW}, sql.And(
+		sql.I("id").Eq(id),
+		sql.I("purpose").Eq(purpose),
+		sql.I("used_at").IsNull(),
+		sql.I("expires_at").Gt(sql.NOW),
+		sql.L(`EXISTS (SELECT 1 FROM "users" AS u WHERE u.id = "email_tokens"."user_id" AND u.email = "email_tokens"."email")`),
+	))
+	if pg.IsNotFound(err) {
+		return nil, ErrInvalid
+	}
+	return token, err
+}
+
+// Revoke: Invalidate the unused tokens of a user for a purpose
+func Revoke(ctx context.Context, tx pg.Tx, userID pgtype.UUID, purpose model.Purpose) error {
+	_, err := sql.HardDelete(ctx, tx, model.EmailToken{}, sql.And(
+		sql.I("user_id").Eq(userID),
+		sql.I("purpose").Eq(purpose),
+		sql.I("used_at").IsNull(),
+	))
+	return err
+}
+
+// Sign: Signature of a payload, for values round-tripped through a client
+func Sign(payload string) string {
+	return encoding.EncodeToString(sign(payload))
+}
+
+// Verify: Check the signature of a payload
+func Verify(payload, signature string) bool {
+	decoded, err := encoding.DecodeString(signature)
+	return err == nil && hmac.Equal(decoded, sign(payload))
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+func sign(payload string) []byte {
+	mac := hmac.New(sha256.New, key())
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// verify: Check the signature, purpose and expiry, returns the row ID
+func verify(plain string, purpose model.Purpose) (pgtype.UUID, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(plain, ".")
+	if !ok {
+		return pgtype.UUID{}, ErrInvalid
+	}
+	payload, err := encoding.DecodeString(encodedPayload)
+	if err != nil {
+		return pgtype.UUID{}, ErrInvalid
+	}
+	signature, err := encoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, sign(string(payload))) {
+		return pgtype.UUID{}, ErrInvalid
+	}
+
+	parts := strings.Split(string(payload), ".")
+	if len(parts) != 3 || parts[0] != string(purpose) {
+		return pgtype.UUID{}, ErrInvalid
+	}
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return pgtype.UUID{}, ErrInvalid
+	}
+	id, err := pg.ParseUUID(parts[1])
+	if err != nil {
+		return pgtype.UUID{}, ErrInvalid
+	}
+	return id, nil
+}
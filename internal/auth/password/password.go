@@ -0,0 +1,79 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	errors "emperror.dev/errors"
+	argon2 "golang.org/x/crypto/argon2"
+)
+
+// Argon2id parameters, encoded in every hash so they can evolve
+const (
+	memory  = 64 * 1024
+	time    = 3
+	threads = 2
+	saltLen = 16
+	keyLen  = 32
+)
+
+var encoding = base64.RawStdEncoding
+
+// Hash: Hash a password with argon2id, in the PHC string format
+func Hash(password string) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.WithStack(err)
+	}
+	key := argon2.IDKey([]byte(password), salt, time, memory, threads, keyLen)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, memory, time, threads,
+		encoding.EncodeToString(salt), encoding.EncodeToString(key),
+	), nil
+}
+
+// Verify: Check a password against a hash
+func Verify(password, hash string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, errors.Errorf("unsupported argon2 version `%s`", parts[2])
+	}
+	var m, t uint32
+	var p uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &m, &t, &p); err != nil {
+		return false, errors.WithStack(err)
+	}
+	salt, err := encoding.DecodeString(parts[4])
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	key, err := encoding.DecodeString(parts[5])
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	other := argon2.IDKey([]byte(password), salt, t, m, p, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+var (
+	// Hash verified when the user does not exist, to keep timings even
+	dummy     string
+	dummyOnce sync.Once
+)
+
+// VerifyDummy: Spend the time of a verification without a hash
+func VerifyDummy(password string) {
+	dummyOnce.Do(func() { dummy, _ = Hash("dummy password") })
+	_, _ = Verify(password, dummy)
+}
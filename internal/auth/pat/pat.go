@@ -0,0 +1,105 @@
+package pat
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	model "movies/internal/auth/model"
+	token "movies/internal/auth/token"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	errors "emperror.dev/errors"
+	pgtype "github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+)
+
+// Prefix of every token, telling them apart from JWT access tokens
+const Prefix = "mvp_"
+
+// Minimum delay between two updates of the last use of a token
+const touchInterval = time.Minute
+
+var ErrInvalid = errors.New("invalid personal access token")
+
+// Is: Whether a bearer token is a personal access token
+func Is(bearer string) bool {
+	return strings.HasPrefix(bearer, Prefix)
+}
+
+// Create: Issue a token for a user, the plaintext is only returned here
+func Create(ctx context.Context, tx pg.Tx, userID pgtype.UUID, body model.CreateToken) (*model.CreatedToken, error) {
+	plain, err := token.New()
+	if err != nil {
+		return nil, err
+	}
+	plain = Prefix + plain
+
+	scopes, err := pg.PrimitiveToTextArray(body.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := pgtype.Timestamptz{Status: pgtype.Null}
+	if body.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *body.ExpiresAt, Status: pgtype.Present}
+	}
+
+	created := &model.PersonalAccessToken{}
+	if err := sql.Create(ctx, tx, created, sql.Record{
+		"user_id":    userID,
+		"name":       body.Name,
+		"token_hash": token.Hash(plain),
+		"scopes":     scopes,
+		"expires_at": expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+	return &model.CreatedToken{PersonalAccessToken: created, Token: plain}, nil
+}
+
+// Authenticate: Get the live token of a plaintext and record its use
+func Authenticate(ctx context.Context, tx pg.Tx, plain, ip string) (*model.PersonalAccessToken, error) {
+	found, err := sql.Read[model.PersonalAccessToken]().
+		Where(
+			sql.I("token_hash").Eq(token.Hash(plain)),
+			sql.Or(sql.I("expires_at").IsNull(), sql.I("expires_at").Gt(sql.NOW)),
+		).
+		FindOne(ctx, tx)
+	if pg.IsNotFound(err) {
+		return nil, ErrInvalid
+	} else if err != nil {
+		return nil, err
+	}
+
+	if found.LastUsedAt.Status != pgtype.Present || time.Since(found.LastUsedAt.Time) > touchInterval || found.LastUsedIP.String != ip {
+		err := sql.Update(ctx, tx, found, false, sql.Record{
+			"last_used_at": sql.NOW,
+			"last_used_ip": ip,
+		}, sql.I("id").Eq(found.ID))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
+
+// List: Tokens of a user, newest first
+func List(ctx context.Context, tx pg.Tx, userID pgtype.UUID) ([]*model.PersonalAccessToken, error) {
+	return sql.Read[model.PersonalAccessToken]().
+		Where(sql.I("user_id").Eq(userID)).
+		Order(sql.I("created_at").Desc()).
+		FindAll(ctx, tx)
+}
+
+// Delete: Revoke a token of a user
+func Delete(ctx context.Context, tx pg.Tx, userID, id pgtype.UUID) error {
+	count, err := sql.HardDelete(ctx, tx, model.PersonalAccessToken{}, sql.And(
+		sql.I("id").Eq(id),
+		sql.I("user_id").Eq(userID),
+	))
+	if err == nil && count == 0 {
+		return pgx.ErrNoRows
+	}
+	return err
+}
@@ -0,0 +1,142 @@
+package refresh
+
+import (
+	"context"
+	"time"
+
+	jwt "movies/internal/auth/jwt"
+	model "movies/internal/auth/model"
+	token "movies/internal/auth/token"
+	config "movies/utils/config"
+	logger "movies/utils/logger"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	errors "emperror.dev/errors"
+	exp "github.com/doug-martin/goqu/v9/exp"
+	pgtype "github.com/jackc/pgtype"
+)
+
+var (
+	ErrInvalid = errors.New("invalid refresh token")
+	ErrReused  = errors.New("refresh token reused")
+)
+
+// Issue: Start a new token family for a user
+func Issue(ctx context.Context, tx pg.Tx, userID pgtype.UUID) (*model.TokenPair, error) {
+	return issue(ctx, tx, userID, pg.NewUUID(), pg.NullUUID())
+}
+
+// Rotate: Exchange a refresh token for a new pair
+//
+// A refresh token can be used once. Presenting it again revokes its whole
+// family, as either the client or an attacker holds a stolen copy. The
+// rotation runs in its own transaction so the revocation survives the error.
+func Rotate(ctx context.Context, plain string) (*model.TokenPair, error) {
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := sql.Read[model.RefreshToken]().
+		Where(sql.I("token_hash").Eq(token.Hash(plain))).
+		ForUpdate().
+		FindOne(ctx, tx)
+	if pg.IsNotFound(err) {
+		return nil, ErrInvalid
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case current.RevokedAt.Status == pgtype.Present:
+		return nil, ErrInvalid
+	case current.UsedAt.Status == pgtype.Present:
+		logger.With("family_id", pg.FormatUUID(current.FamilyID)).Warn(ctx, "Refresh token reused, family revoked")
+		if err := RevokeFamily(ctx, tx, current.FamilyID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		return nil, ErrReused
+	case !current.ExpiresAt.Time.After(time.Now()):
+		return nil, ErrInvalid
+	}
+
+	if err := sql.Update(ctx, tx, current, false,
+		sql.Record{"used_at": sql.NOW},
+		sql.I("id").Eq(current.ID),
+	); err != nil {
+		return nil, err
+	}
+	pair, err := issue(ctx, tx, current.UserID, current.FamilyID, current.ID)
+	if err != nil {
+		return nil, err
+	}
+	return pair, tx.Commit(ctx)
+}
+
+// Revoke: Revoke the family of a refresh token
+func Revoke(ctx context.Context, tx pg.Tx, plain string) error {
+	current, err := sql.Read[model.RefreshToken]().
+		Where(sql.I("token_hash").Eq(token.Hash(plain))).
+		FindOne(ctx, tx)
+	if pg.IsNotFound(err) {
+		return ErrInvalid
+	} else if err != nil {
+		return err
+	}
+	return RevokeFamily(ctx, tx, current.FamilyID)
+}
+
+// RevokeFamily: Revoke every token of a family
+func RevokeFamily(ctx context.Context, tx pg.Tx, familyID pgtype.UUID) error {
+	return revoke(ctx, tx, sql.I("family_id").Eq(familyID))
+}
+
+// RevokeUser: Revoke every token of a user
+func RevokeUser(ctx context.Context, tx pg.Tx, userID pgtype.UUID) error {
+	return revoke(ctx, tx, sql.I("user_id").Eq(userID))
+}
+
+func revoke(ctx context.Context, tx pg.Tx, expression exp.Expression) error {
+	query, args, err := pg.SQLBuilder().
+		Update(model.RefreshToken{}.TableName()).
+		Set(sql.Record{"revoked_at": sql.NOW}).
+		Where(expression, sql.I("revoked_at").IsNull()).
+		ToSQL()
+	if err != nil {
+		return err
+	}
+	_, err = pg.Client(tx).Exec(ctx, query, args...)
+	return err
+}
+
+func issue(ctx context.Context, tx pg.Tx, userID, familyID, parentID pgtype.UUID) (*model.TokenPair, error) {
+	plain, err := token.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := sql.Create(ctx, tx, &model.RefreshToken{}, sql.Record{
+		"user_id":    userID,
+		"family_id":  familyID,
+		"parent_id":  parentID,
+		"token_hash": token.Hash(plain),
+		"expires_at": time.Now().Add(config.Auth().RefreshTTL()),
+	}); err != nil {
+		return nil, err
+	}
+
+	access, err := jwt.Sign(jwt.NewClaims(pg.FormatUUID(userID)))
+	if err != nil {
+		return nil, err
+	}
+	return &model.TokenPair{
+		AccessToken:  access,
+		RefreshToken: plain,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(config.Auth().AccessTTL().Seconds()),
+	}, nil
+}
@@ -0,0 +1,196 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	mail "movies/internal/auth/mail"
+	middleware "movies/internal/auth/middleware"
+	model "movies/internal/auth/model"
+	onetime "movies/internal/auth/onetime"
+	password "movies/internal/auth/password"
+	refresh "movies/internal/auth/refresh"
+	session "movies/internal/auth/session"
+	user "movies/internal/user/model"
+	cerrors "movies/utils/cerrors"
+	config "movies/utils/config"
+	form "movies/utils/form"
+	logger "movies/utils/logger"
+	pg "movies/utils/pg"
+	render "movies/utils/render"
+	sql "movies/utils/sql"
+
+	pgtype "github.com/jackc/pgtype"
+)
+
+/*============================================================================*/
+/*=====*                       Email verification                       *=====*/
+/*============================================================================*/
+
+// verifyEmail: Confirm the email address of a user
+func (obj *AuthRouter) verifyEmail(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var body model.EmailTokenBody
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	token, err := onetime.Consume(ctx, tx, body.Token, model.PurposeVerifyEmail)
+	if err != nil {
+		return tokenError(err)
+	}
+	u := &user.User{}
+	if err := sql.Update(ctx, tx, u, true, sql.Record{"email_verified_at": sql.NOW}, sql.I("id").Eq(token.UserID)); err != nil {
+		return err
+	}
+	if err := onetime.Revoke(ctx, tx, u.ID, model.PurposeVerifyEmail); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, u)
+	return nil
+}
+
+// resendVerification: Email a new verification link to the current user
+func (obj *AuthRouter) resendVerification(w http.ResponseWriter, r *http.Request) error {
+	if !middleware.Unscoped(w, r) {
+		return nil
+	}
+	u, _ := middleware.CurrentUser(r.Context())
+	if u.EmailVerifiedAt.Status == pgtype.Present {
+		return render.NewStatus(http.StatusConflict, cerrors.NewString("Email is already verified"))
+	}
+
+	if err := issueEmail(r.Context(), u, model.PurposeVerifyEmail); err != nil {
+		return err
+	}
+	render.NoContent(w)
+	return nil
+}
+
+/*============================================================================*/
+/*=====*                         Password reset                         *=====*/
+/*============================================================================*/
+
+// forgotPassword: Email a password reset link
+//
+// The response does not tell whether the email belongs to an account.
+func (obj *AuthRouter) forgotPassword(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var body model.ForgotPassword
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+
+	u, err := sql.Read[user.User]().Where(sql.I("email").Eq(body.Email)).FindOne(ctx, pg.EmptyTx())
+	if err != nil && !pg.IsNotFound(err) {
+		return err
+	} else if err == nil {
+		// Off the request path, so both answers take the same time
+		ctx := detached{ctx}
+		go func() {
+			if err := issueEmail(ctx, u, model.PurposeResetPassword); err != nil {
+				logger.With("user_id", pg.FormatUUID(u.ID)).Error(ctx, "Password reset email failed: %v", err)
+			}
+		}()
+	}
+	render.Accepted(w)
+	return nil
+}
+
+// resetPassword: Choose a new password, closing every session of the user
+func (obj *AuthRouter) resetPassword(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var body model.ResetPassword
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+	hash, err := password.Hash(body.Password)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	token, err := onetime.Consume(ctx, tx, body.Token, model.PurposeResetPassword)
+	if err != nil {
+		return tokenError(err)
+	}
+	// The link proves the ownership of the address
+	u := &user.User{}
+	if err := sql.Update(ctx, tx, u, true, sql.Record{
+		"password_hash":     hash,
+		"email_verified_at": sql.L("COALESCE(?, NOW())", sql.I("email_verified_at")),
+	}, sql.I("id").Eq(token.UserID)); err != nil {
+		return err
+	}
+
+	if err := onetime.Revoke(ctx, tx, u.ID, model.PurposeResetPassword); err != nil {
+		return err
+	}
+	if err := session.DeleteUser(ctx, tx, u.ID); err != nil {
+		return err
+	}
+	if err := refresh.RevokeUser(ctx, tx, u.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	session.ClearCookie(w)
+	render.NoContent(w)
+	return nil
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+// issueEmail: Create a token for a purpose and email its link
+func issueEmail(ctx context.Context, u *user.User, purpose model.Purpose) error {
+	ttl, send := config.Auth().VerificationTTL(), mail.SendVerification
+	if purpose == model.PurposeResetPassword {
+		ttl, send = config.Auth().PasswordResetTTL(), mail.SendPasswordReset
+	}
+
+	token, err := onetime.Issue(ctx, pg.EmptyTx(), u, purpose, ttl)
+	if err != nil {
+		return err
+	}
+	return send(ctx, u, token)
+}
+
+// detached: Context keeping the values of a request, but neither its deadline
+// nor its cancellation, for work outliving the response
+type detached struct{ context.Context }
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+
+// tokenError: Error of a one-time token as rendered to the client
+func tokenError(err error) error {
+	if errors.Is(err, onetime.ErrInvalid) {
+		return cerrors.NewValidation("token", "token", "Invalid or expired token", nil)
+	}
+	return err
+}
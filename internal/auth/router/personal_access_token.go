@@ -0,0 +1,79 @@
+package router
+
+import (
+	"net/http"
+	"time"
+
+	middleware "movies/internal/auth/middleware"
+	model "movies/internal/auth/model"
+	pat "movies/internal/auth/pat"
+	cerrors "movies/utils/cerrors"
+	form "movies/utils/form"
+	pg "movies/utils/pg"
+	principal "movies/utils/principal"
+	render "movies/utils/render"
+
+	mux "github.com/gorilla/mux"
+)
+
+/*============================================================================*/
+/*=====*                     Personal access tokens                     *=====*/
+/*============================================================================*/
+
+// listTokens: Personal access tokens of the current user
+func (obj *AuthRouter) listTokens(w http.ResponseWriter, r *http.Request) error {
+	if !middleware.Unscoped(w, r) {
+		return nil
+	}
+	p, _ := principal.FromContext(r.Context())
+
+	tokens, err := pat.List(r.Context(), pg.EmptyTx(), p.UserID)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, tokens)
+	return nil
+}
+
+// createToken: Issue a personal access token, the plaintext is only shown once
+func (obj *AuthRouter) createToken(w http.ResponseWriter, r *http.Request) error {
+	if !middleware.Unscoped(w, r) {
+		return nil
+	}
+	p, _ := principal.FromContext(r.Context())
+
+	var body model.CreateToken
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		return cerrors.NewValidation("future", "expires_at", "`expires_at` must be in the future", body.ExpiresAt)
+	}
+
+	created, err := pat.Create(r.Context(), pg.EmptyTx(), p.UserID, body)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusCreated, created)
+	return nil
+}
+
+// deleteToken: Revoke a personal access token
+func (obj *AuthRouter) deleteToken(w http.ResponseWriter, r *http.Request) error {
+	if !middleware.Unscoped(w, r) {
+		return nil
+	}
+	p, _ := principal.FromContext(r.Context())
+
+	value := mux.Vars(r)["id"]
+	id, err := pg.ParseUUID(value)
+	if err != nil {
+		return cerrors.NewValidation("uuid", "id", "`"+value+"` is not a valid UUID", value)
+	}
+
+	if err := pat.Delete(r.Context(), pg.EmptyTx(), p.UserID, id); err != nil {
+		return err
+	}
+	render.NoContent(w)
+	return nil
+}
@@ -0,0 +1,228 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+
+	jwt "movies/internal/auth/jwt"
+	model "movies/internal/auth/model"
+	password "movies/internal/auth/password"
+	refresh "movies/internal/auth/refresh"
+	session "movies/internal/auth/session"
+	twofactor "movies/internal/auth/twofactor"
+	user "movies/internal/user/model"
+	cerrors "movies/utils/cerrors"
+	form "movies/utils/form"
+	logger "movies/utils/logger"
+	pg "movies/utils/pg"
+	render "movies/utils/render"
+	sql "movies/utils/sql"
+
+	mux "github.com/gorilla/mux"
+	pgtype "github.com/jackc/pgtype"
+)
+
+type AuthRouter struct {
+	router *mux.Router
+}
+
+func NewAuthRouter(r *mux.Router) *AuthRouter {
+	return &AuthRouter{router: r}
+}
+
+func (obj *AuthRouter) Handle() {
+	s := obj.router.PathPrefix("/auth").Subrouter()
+	s.HandleFunc("/register", render.Handler(obj.register)).Methods(http.MethodPost)
+	s.HandleFunc("/login", render.Handler(obj.login)).Methods(http.MethodPost)
+	s.HandleFunc("/logout", render.Handler(obj.logout)).Methods(http.MethodPost)
+	s.HandleFunc("/token", render.Handler(obj.token)).Methods(http.MethodPost)
+	s.HandleFunc("/token/refresh", render.Handler(obj.refresh)).Methods(http.MethodPost)
+	s.HandleFunc("/token/revoke", render.Handler(obj.revoke)).Methods(http.MethodPost)
+
+	s.HandleFunc("/email/verify", render.Handler(obj.verifyEmail)).Methods(http.MethodPost)
+	s.HandleFunc("/email/resend", render.Handler(obj.resendVerification)).Methods(http.MethodPost)
+	s.HandleFunc("/password/forgot", render.Handler(obj.forgotPassword)).Methods(http.MethodPost)
+	s.HandleFunc("/password/reset", render.Handler(obj.resetPassword)).Methods(http.MethodPost)
+
+	s.HandleFunc("/tokens", render.Handler(obj.listTokens)).Methods(http.MethodGet)
+	s.HandleFunc("/tokens", render.Handler(obj.createToken)).Methods(http.MethodPost)
+	s.HandleFunc("/tokens/{id}", render.Handler(obj.deleteToken)).Methods(http.MethodDelete)
+
+	s.HandleFunc("/2fa/enrol", render.Handler(obj.enrol)).Methods(http.MethodPost)
+	s.HandleFunc("/2fa/enable", render.Handler(obj.enable)).Methods(http.MethodPost)
+	s.HandleFunc("/2fa/disable", render.Handler(obj.disable)).Methods(http.MethodPost)
+	s.HandleFunc("/2fa/recovery-codes", render.Handler(obj.recoveryCodes)).Methods(http.MethodPost)
+
+	obj.router.HandleFunc("/.well-known/jwks.json", render.Handler(obj.jwks)).Methods(http.MethodGet)
+}
+
+// register: Create an account, open a session and email a verification link
+func (obj *AuthRouter) register(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var body model.Register
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+	hash, err := password.Hash(body.Password)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	u := &user.User{}
+	if err := sql.Create(ctx, tx, u, sql.Record{
+		"username":      body.Username,
+		"email":         body.Email,
+		"password_hash": hash,
+	}); err != nil {
+		return err
+	}
+
+	plain, s, err := session.Create(ctx, tx, u.ID, r)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if err := issueEmail(ctx, u, model.PurposeVerifyEmail); err != nil {
+		logger.With("user_id", pg.FormatUUID(u.ID)).Error(ctx, "Verification email failed: %v", err)
+	}
+
+	session.SetCookie(w, plain, s)
+	render.JSON(w, r, http.StatusCreated, u)
+	return nil
+}
+
+// login: Open a session from credentials
+func (obj *AuthRouter) login(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	u, err := authenticate(r)
+	if err != nil {
+		return err
+	}
+
+	plain, s, err := session.Create(ctx, pg.EmptyTx(), u.ID, r)
+	if err != nil {
+		return err
+	}
+	session.SetCookie(w, plain, s)
+	render.JSON(w, r, http.StatusOK, u)
+	return nil
+}
+
+// logout: Close the current session
+func (obj *AuthRouter) logout(w http.ResponseWriter, r *http.Request) error {
+	if plain, ok := session.Cookie(r); ok {
+		if err := session.Delete(r.Context(), pg.EmptyTx(), plain); err != nil {
+			return err
+		}
+	}
+	session.ClearCookie(w)
+	render.NoContent(w)
+	return nil
+}
+
+// token: Issue an access and refresh token pair from credentials
+func (obj *AuthRouter) token(w http.ResponseWriter, r *http.Request) error {
+	u, err := authenticate(r)
+	if err != nil {
+		return err
+	}
+
+	pair, err := refresh.Issue(r.Context(), pg.EmptyTx(), u.ID)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, pair)
+	return nil
+}
+
+// refresh: Rotate a refresh token
+func (obj *AuthRouter) refresh(w http.ResponseWriter, r *http.Request) error {
+	var body model.Refresh
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+
+	pair, err := refresh.Rotate(r.Context(), body.RefreshToken)
+	if errors.Is(err, refresh.ErrInvalid) || errors.Is(err, refresh.ErrReused) {
+		return render.NewStatus(http.StatusUnauthorized, cerrors.NewString("Invalid refresh token"))
+	} else if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, pair)
+	return nil
+}
+
+// revoke: Revoke the family of a refresh token
+func (obj *AuthRouter) revoke(w http.ResponseWriter, r *http.Request) error {
+	var body model.Refresh
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+
+	if err := refresh.Revoke(r.Context(), pg.EmptyTx(), body.RefreshToken); err != nil && !errors.Is(err, refresh.ErrInvalid) {
+		return err
+	}
+	render.NoContent(w)
+	return nil
+}
+
+// jwks: Public keys verifying access tokens
+func (obj *AuthRouter) jwks(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	render.JSON(w, r, http.StatusOK, jwt.JWKS())
+	return nil
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+// authenticate: Check the credentials of the request body
+//
+// Users with two-factor enabled also need a TOTP or recovery code.
+func authenticate(r *http.Request) (*user.User, error) {
+	invalid := render.NewStatus(http.StatusUnauthorized, cerrors.NewString("Invalid email or password"))
+
+	var body model.Login
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return nil, err
+	}
+
+	u, err := sql.Read[user.User]().Where(sql.I("email").Eq(body.Email)).FindOne(r.Context(), pg.EmptyTx())
+	if err != nil && !pg.IsNotFound(err) {
+		return nil, err
+	}
+	if err != nil || u.PasswordHash.Status != pgtype.Present {
+		password.VerifyDummy(body.Password)
+		return nil, invalid
+	}
+	if ok, err := password.Verify(body.Password, u.PasswordHash.String); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, invalid
+	}
+
+	if u.HasTwoFactor() {
+		if body.Code == "" {
+			return nil, render.NewStatus(http.StatusUnauthorized, cerrors.NewString("Two-factor code required"))
+		}
+		err := twofactor.Verify(r.Context(), pg.EmptyTx(), u, body.Code)
+		if errors.Is(err, twofactor.ErrInvalidCode) {
+			return nil, render.NewStatus(http.StatusUnauthorized, cerrors.NewString("Invalid two-factor code"))
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
@@ -0,0 +1,139 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+
+	middleware "movies/internal/auth/middleware"
+	model "movies/internal/auth/model"
+	twofactor "movies/internal/auth/twofactor"
+	user "movies/internal/user/model"
+	cerrors "movies/utils/cerrors"
+	form "movies/utils/form"
+	pg "movies/utils/pg"
+	render "movies/utils/render"
+	sql "movies/utils/sql"
+)
+
+/*============================================================================*/
+/*=====*                           Two-factor                           *=====*/
+/*============================================================================*/
+
+// enrol: Start a TOTP enrolment, the URI is rendered as a QR code
+func (obj *AuthRouter) enrol(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if !middleware.Unscoped(w, r) {
+		return nil
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	u, err := lockCurrentUser(r, tx)
+	if err != nil {
+		return err
+	}
+	enrolment, err := twofactor.Enrol(ctx, tx, u)
+	if err != nil {
+		return twoFactorError(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, enrolment)
+	return nil
+}
+
+// enable: Confirm the enrolment with a first code
+func (obj *AuthRouter) enable(w http.ResponseWriter, r *http.Request) error {
+	return twoFactorAction(w, r, func(tx pg.Tx, u *user.User, code string) (any, error) {
+		codes, err := twofactor.Enable(r.Context(), tx, u, code)
+		return model.RecoveryCodes{RecoveryCodes: codes}, err
+	})
+}
+
+// disable: Turn two-factor off
+func (obj *AuthRouter) disable(w http.ResponseWriter, r *http.Request) error {
+	return twoFactorAction(w, r, func(tx pg.Tx, u *user.User, code string) (any, error) {
+		return nil, twofactor.Disable(r.Context(), tx, u, code)
+	})
+}
+
+// recoveryCodes: Replace the recovery codes
+func (obj *AuthRouter) recoveryCodes(w http.ResponseWriter, r *http.Request) error {
+	return twoFactorAction(w, r, func(tx pg.Tx, u *user.User, code string) (any, error) {
+		if err := twofactor.Verify(r.Context(), tx, u, code); err != nil {
+			return nil, err
+		}
+		codes, err := twofactor.RegenerateRecoveryCodes(r.Context(), tx, u)
+		return model.RecoveryCodes{RecoveryCodes: codes}, err
+	})
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+// twoFactorAction: Run an action confirmed by a code on the current user
+func twoFactorAction(w http.ResponseWriter, r *http.Request, action func(pg.Tx, *user.User, string) (any, error)) error {
+	ctx := r.Context()
+
+	if !middleware.Unscoped(w, r) {
+		return nil
+	}
+	var body model.TwoFactor
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	u, err := lockCurrentUser(r, tx)
+	if err != nil {
+		return err
+	}
+	result, err := action(tx, u, body.Code)
+	if err != nil {
+		return twoFactorError(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if result == nil {
+		render.NoContent(w)
+		return nil
+	}
+	render.JSON(w, r, http.StatusOK, result)
+	return nil
+}
+
+// lockCurrentUser: Reload the authenticated user, locked for the transaction
+//
+// Callers reject personal access tokens first, they cannot change the
+// credentials of their user.
+func lockCurrentUser(r *http.Request, tx pg.Tx) (*user.User, error) {
+	current, _ := middleware.CurrentUser(r.Context())
+	return sql.Read[user.User]().Where(sql.I("id").Eq(current.ID)).ForUpdate().FindOne(r.Context(), tx)
+}
+
+// twoFactorError: Error of a two-factor action as rendered to the client
+func twoFactorError(err error) error {
+	switch {
+	case errors.Is(err, twofactor.ErrInvalidCode):
+		return cerrors.NewValidation("totp", "code", "Invalid two-factor code", nil)
+	case errors.Is(err, twofactor.ErrEnabled):
+		return render.NewStatus(http.StatusConflict, cerrors.NewString("Two-factor is already enabled"))
+	case errors.Is(err, twofactor.ErrNotEnrolled):
+		return render.NewStatus(http.StatusConflict, cerrors.NewString("Two-factor is not enrolled"))
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package session
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	model "movies/internal/auth/model"
+	token "movies/internal/auth/token"
+	config "movies/utils/config"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	pgtype "github.com/jackc/pgtype"
+)
+
+/*============================================================================*/
+/*=====*                            Session                             *=====*/
+/*============================================================================*/
+
+// Create: Open a session for a user, the plaintext token is only returned here
+func Create(ctx context.Context, tx pg.Tx, userID pgtype.UUID, r *http.Request) (string, *model.Session, error) {
+	plain, err := token.New()
+	if err != nil {
+		return "", nil, err
+	}
+
+	session := &model.Session{}
+	return plain, session, sql.Create(ctx, tx, session, sql.Record{
+		"user_id":    userID,
+		"token_hash": token.Hash(plain),
+		"ip":         ClientIP(r),
+		"user_agent": r.UserAgent(),
+		"expires_at": time.Now().Add(config.Auth().SessionTTL()),
+	})
+}
+
+// Find: Get the live session of a token
+func Find(ctx context.Context, tx pg.Tx, plain string) (*model.Session, error) {
+	return sql.Read[model.Session]().
+		Where(
+			sql.I("token_hash").Eq(token.Hash(plain)),
+			sql.I("expires_at").Gt(sql.NOW),
+		).
+		FindOne(ctx, tx)
+}
+
+// Delete: Close the session of a token
+func Delete(ctx context.Context, tx pg.Tx, plain string) error {
+	_, err := sql.HardDelete(ctx, tx, model.Session{}, sql.I("token_hash").Eq(token.Hash(plain)))
+	return err
+}
+
+// DeleteUser: Close every session of a user
+func DeleteUser(ctx context.Context, tx pg.Tx, userID pgtype.UUID) error {
+	_, err := sql.HardDelete(ctx, tx, model.Session{}, sql.I("user_id").Eq(userID))
+	return err
+}
+
+// ClientIP: Address of the client of a request
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+/*============================================================================*/
+/*=====*                             Cookie                             *=====*/
+/*============================================================================*/
+
+// SetCookie: Send the session cookie
+func SetCookie(w http.ResponseWriter, plain string, session *model.Session) {
+	http.SetCookie(w, cookie(plain, session.ExpiresAt.Time))
+}
+
+// ClearCookie: Expire the session cookie
+func ClearCookie(w http.ResponseWriter) {
+	c := cookie("", time.Unix(0, 0))
+	c.MaxAge = -1
+	http.SetCookie(w, c)
+}
+
+// Cookie: Session token sent with a request
+func Cookie(r *http.Request) (string, bool) {
+	c, err := r.Cookie(config.Auth().CookieName())
+	if err != nil || c.Value == "" {
+		return "", false
+	}
+	return c.Value, true
+}
+
+func cookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     config.Auth().CookieName(),
+		Value:    value,
+		Path:     "/",
+		Domain:   config.Auth().CookieDomain(),
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   config.Auth().CookieSecure(),
+		SameSite: config.Auth().CookieSameSite(),
+	}
+}
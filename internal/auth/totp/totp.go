@@ -0,0 +1,128 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	errors "emperror.dev/errors"
+)
+
+// Time-based one-time passwords (RFC 6238), with the defaults every
+// authenticator app supports: HMAC-SHA1, 6 digits and 30 seconds steps.
+//
+// Every function takes the time explicitly so codes are deterministic.
+
+const (
+	Digits = 6
+	Period = 30 * time.Second
+	// Steps accepted before and after the current one, for clock drift
+	Skew = 1
+)
+
+// Size of generated secrets, in bytes
+const secretSize = 20
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var ErrSecret = errors.New("invalid TOTP secret")
+
+// NewSecret: Generate a random base32 secret
+func NewSecret() (string, error) {
+	buf := make([]byte, secretSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return encoding.EncodeToString(buf), nil
+}
+
+// URI: Provisioning URI of a secret, rendered as a QR code by clients
+func URI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Counter: Time step of an instant
+func Counter(at time.Time) int64 {
+	return at.Unix() / int64(Period.Seconds())
+}
+
+// Code: One-time password of a secret at an instant
+func Code(secret string, at time.Time) (string, error) {
+	key, err := decode(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, Counter(at)), nil
+}
+
+// Validate: Check a code at an instant, within the allowed skew
+//
+// The matching counter is returned so callers can reject codes of a step
+// already used, passed back as `last`.
+func Validate(secret, value string, at time.Time, last int64) (int64, bool, error) {
+	key, err := decode(secret)
+	if err != nil {
+		return 0, false, err
+	}
+
+	value = strings.TrimSpace(value)
+	if len(value) != Digits {
+		return 0, false, nil
+	}
+	current := Counter(at)
+	for counter := current - Skew; counter <= current+Skew; counter++ {
+		if counter <= last {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(code(key, counter)), []byte(value)) == 1 {
+			return counter, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+func decode(secret string) ([]byte, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(key) == 0 {
+		return nil, ErrSecret
+	}
+	return key, nil
+}
+
+// code: HOTP value of a counter (RFC 4226)
+func code(key []byte, counter int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%mod)
+}
@@ -0,0 +1,198 @@
+package twofactor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	model "movies/internal/auth/model"
+	token "movies/internal/auth/token"
+	totp "movies/internal/auth/totp"
+	user "movies/internal/user/model"
+	config "movies/utils/config"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	errors "emperror.dev/errors"
+	pgtype "github.com/jackc/pgtype"
+)
+
+// Clock: Current time of TOTP checks, replaced for deterministic codes
+var Clock = time.Now
+
+// Number of recovery codes generated at once
+const RecoveryCodeCount = 10
+
+var (
+	ErrEnabled     = errors.New("two-factor is already enabled")
+	ErrNotEnrolled = errors.New("two-factor is not enrolled")
+	ErrInvalidCode = errors.New("invalid two-factor code")
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+/*============================================================================*/
+/*=====*                           Enrolment                            *=====*/
+/*============================================================================*/
+
+// Enrol: Start an enrolment with a new secret, replacing a pending one
+func Enrol(ctx context.Context, tx pg.Tx, u *user.User) (*model.Enrolment, error) {
+	if u.HasTwoFactor() {
+		return nil, ErrEnabled
+	}
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sql.UpdateByPK(ctx, tx, u, true, sql.Record{
+		"totp_secret":       secret,
+		"totp_last_counter": 0,
+	}); err != nil {
+		return nil, err
+	}
+
+	account := u.Username
+	if u.Email.Status == pgtype.Present {
+		account = u.Email.String
+	}
+	return &model.Enrolment{
+		Secret: secret,
+		URI:    totp.URI(config.Auth().TOTPIssuer(), account, secret),
+	}, nil
+}
+
+// Enable: Confirm an enrolment with a first code, returns the recovery codes
+func Enable(ctx context.Context, tx pg.Tx, u *user.User, code string) ([]string, error) {
+	if u.HasTwoFactor() {
+		return nil, ErrEnabled
+	} else if u.TOTPSecret.Status != pgtype.Present {
+		return nil, ErrNotEnrolled
+	}
+	if err := verifyTOTP(ctx, tx, u, code); err != nil {
+		return nil, err
+	}
+
+	if err := sql.UpdateByPK(ctx, tx, u, true, sql.Record{"totp_enabled_at": sql.NOW}); err != nil {
+		return nil, err
+	}
+	return RegenerateRecoveryCodes(ctx, tx, u)
+}
+
+// Disable: Remove the secret and recovery codes, after checking a code
+func Disable(ctx context.Context, tx pg.Tx, u *user.User, code string) error {
+	if err := Verify(ctx, tx, u, code); err != nil {
+		return err
+	}
+
+	if err := sql.UpdateByPK(ctx, tx, u, true, sql.Record{
+		"totp_secret":       nil,
+		"totp_enabled_at":   nil,
+		"totp_last_counter": 0,
+	}); err != nil {
+		return err
+	}
+	_, err := sql.HardDelete(ctx, tx, model.RecoveryCode{}, sql.I("user_id").Eq(u.ID))
+	return err
+}
+
+/*============================================================================*/
+/*=====*                          Verification                          *=====*/
+/*============================================================================*/
+
+// Verify: Check a TOTP code or consume a recovery code
+func Verify(ctx context.Context, tx pg.Tx, u *user.User, code string) error {
+	if !u.HasTwoFactor() {
+		return ErrNotEnrolled
+	}
+	if err := verifyTOTP(ctx, tx, u, code); !errors.Is(err, ErrInvalidCode) {
+		return err
+	}
+	return useRecoveryCode(ctx, tx, u, code)
+}
+
+// verifyTOTP: Check a TOTP code, rejecting the replay of a used time step
+func verifyTOTP(ctx context.Context, tx pg.Tx, u *user.User, code string) error {
+	counter, err := checkTOTP(u.TOTPSecret.String, code, u.TOTPLastCounter)
+	if err != nil {
+		return err
+	}
+
+	err = sql.Update(ctx, tx, u, false, sql.Record{"totp_last_counter": counter}, sql.And(
+		sql.I("id").Eq(u.ID),
+		sql.I("totp_last_counter").Lt(counter),
+	))
+	if pg.IsNotFound(err) {
+		return ErrInvalidCode
+	}
+	return err
+}
+
+// checkTOTP: Time step of a code valid at the Clock, past the `last` one used
+func checkTOTP(secret, code string, last int64) (int64, error) {
+	counter, ok, err := totp.Validate(secret, code, Clock(), last)
+	if err != nil {
+		return 0, err
+	} else if !ok {
+		return 0, ErrInvalidCode
+	}
+	return counter, nil
+}
+
+/*============================================================================*/
+/*=====*                         Recovery codes                         *=====*/
+/*============================================================================*/
+
+// RegenerateRecoveryCodes: Replace the recovery codes of a user
+//
+// Only hashes are stored, the plaintext codes are returned once.
+func RegenerateRecoveryCodes(ctx context.Context, tx pg.Tx, u *user.User) ([]string, error) {
+	if _, err := sql.HardDelete(ctx, tx, model.RecoveryCode{}, sql.I("user_id").Eq(u.ID)); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		if err := sql.Create(ctx, tx, &model.RecoveryCode{}, sql.Record{
+			"user_id":   u.ID,
+			"code_hash": token.Hash(normalize(code)),
+		}); err != nil {
+			return nil, err
+		}
+		codes[i] = code
+	}
+	return codes, nil
+}
+
+func useRecoveryCode(ctx context.Context, tx pg.Tx, u *user.User, code string) error {
+	err := sql.Update(ctx, tx, &model.RecoveryCode{}, false, sql.Record{"used_at": sql.NOW}, sql.And(
+		sql.I("user_id").Eq(u.ID),
+		sql.I("code_hash").Eq(token.Hash(normalize(code))),
+		sql.I("used_at").IsNull(),
+	))
+	if pg.IsNotFound(err) {
+		return ErrInvalidCode
+	}
+	return err
+}
+
+// newRecoveryCode: Random code formatted as `xxxxx-xxxxx`
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.WithStack(err)
+	}
+	code := strings.ToLower(recoveryEncoding.EncodeToString(buf))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalize: Recovery code without separators nor case
+func normalize(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
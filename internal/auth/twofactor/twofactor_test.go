@@ -0,0 +1,184 @@
+package twofactor
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	totp "movies/internal/auth/totp"
+	user "movies/internal/user/model"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	errors "emperror.dev/errors"
+)
+
+// Secret of the RFC 6238 test vectors
+const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Instant in the middle of a time step
+var instant = time.Date(2026, time.October, 19, 12, 0, 15, 0, time.UTC)
+
+func TestCheckTOTPSkew(t *testing.T) {
+	tests := []struct {
+		steps int64
+		valid bool
+	}{
+		{steps: -2, valid: false},
+		{steps: -1, valid: true},
+		{steps: 0, valid: true},
+		{steps: 1, valid: true},
+		{steps: 2, valid: false},
+	}
+	fixClock(t, instant)
+
+	for _, test := range tests {
+		t.Run(strconv.FormatInt(test.steps, 10), func(t *testing.T) {
+			code := codeAt(t, instant.Add(time.Duration(test.steps)*totp.Period))
+			counter, err := checkTOTP(secret, code, 0)
+			if !test.valid {
+				if !errors.Is(err, ErrInvalidCode) {
+					t.Fatalf("expected ErrInvalidCode, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			} else if want := totp.Counter(instant) + test.steps; counter != want {
+				t.Errorf("counter %d, expected %d", counter, want)
+			}
+		})
+	}
+}
+
+func TestCheckTOTPReplay(t *testing.T) {
+	now := fixClock(t, instant)
+	code := codeAt(t, instant)
+	last, err := checkTOTP(secret, code, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same code later in the same step
+	*now = instant.Add(10 * time.Second)
+	if _, err := checkTOTP(secret, code, last); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("replayed code: expected ErrInvalidCode, got %v", err)
+	}
+	// Previous step, still within the skew
+	if _, err := checkTOTP(secret, codeAt(t, instant.Add(-totp.Period)), last); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("older step: expected ErrInvalidCode, got %v", err)
+	}
+
+	// Next step
+	*now = instant.Add(totp.Period)
+	if _, err := checkTOTP(secret, code, last); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("replayed code in the next step: expected ErrInvalidCode, got %v", err)
+	}
+	counter, err := checkTOTP(secret, codeAt(t, *now), last)
+	if err != nil {
+		t.Fatal(err)
+	} else if counter != last+1 {
+		t.Errorf("counter %d, expected %d", counter, last+1)
+	}
+}
+
+func TestVerifyReplay(t *testing.T) {
+	now := fixClock(t, instant)
+	ctx, tx, u, _ := enabledUser(t)
+
+	// The enabling code used the current step
+	if err := Verify(ctx, tx, u, codeAt(t, instant)); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("replayed code: expected ErrInvalidCode, got %v", err)
+	}
+
+	*now = instant.Add(totp.Period)
+	code := codeAt(t, *now)
+	if err := Verify(ctx, tx, u, code); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(ctx, tx, u, code); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("replayed code: expected ErrInvalidCode, got %v", err)
+	}
+}
+
+func TestRecoveryCodeSingleUse(t *testing.T) {
+	fixClock(t, instant)
+	ctx, tx, u, codes := enabledUser(t)
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("%d recovery codes, expected %d", len(codes), RecoveryCodeCount)
+	}
+
+	if err := Verify(ctx, tx, u, codes[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(ctx, tx, u, codes[0]); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("used recovery code: expected ErrInvalidCode, got %v", err)
+	}
+
+	// Separators and case are ignored
+	code := strings.ToUpper(strings.ReplaceAll(codes[1], "-", ""))
+	if err := Verify(ctx, tx, u, code); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(ctx, tx, u, codes[1]); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("used recovery code: expected ErrInvalidCode, got %v", err)
+	}
+}
+
+/*============================================================================*/
+/*=====*                            Helpers                             *=====*/
+/*============================================================================*/
+
+// fixClock: Freeze the Clock, moved through the returned pointer
+func fixClock(t *testing.T, at time.Time) *time.Time {
+	now := at
+	Clock = func() time.Time { return now }
+	t.Cleanup(func() { Clock = time.Now })
+	return &now
+}
+
+func codeAt(t *testing.T, at time.Time) string {
+	code, err := totp.Code(secret, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// enabledUser: User with two-factor enabled at the Clock, in a transaction
+// rolled back after the test
+//
+// It needs a migrated database, configured by the `POSTGRES_*` variables.
+func enabledUser(t *testing.T) (context.Context, pg.Tx, *user.User, []string) {
+	if os.Getenv("POSTGRES_HOST") == "" {
+		t.Skip("POSTGRES_HOST is not set")
+	}
+	ctx := context.Background()
+	tx, err := pg.NewTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tx.RollbackDefer(ctx) })
+
+	u := &user.User{}
+	username := "twofactor" + strconv.FormatInt(Clock().UnixNano(), 36)
+	if err := sql.Create(ctx, tx, u, sql.Record{"username": username}); err != nil {
+		t.Fatal(err)
+	}
+	enrolment, err := Enrol(ctx, tx, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := totp.Code(enrolment.Secret, Clock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := Enable(ctx, tx, u, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx, tx, u, codes
+}
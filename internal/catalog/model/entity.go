@@ -0,0 +1,113 @@
+package model
+
+import (
+	"context"
+	"net/url"
+
+	rbac "movies/internal/rbac/model"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	pgtype "github.com/jackc/pgtype"
+)
+
+// Model: Row of a catalog table
+type Model interface {
+	sql.Revisionable
+	GetDeleted() sql.Deleted
+	GetArchived() sql.Archived
+}
+
+// Entity: Catalog model exposed to community edits
+type Entity struct {
+	// Find a live row of the model
+	Find func(ctx context.Context, tx pg.Tx, id pgtype.UUID) (Model, error)
+	// Find a deleted or archived row of the model
+	FindTrashed func(ctx context.Context, tx pg.Tx, id pgtype.UUID) (Model, error)
+	// Page through live rows of the model, filtered by a list query
+	List func(ctx context.Context, tx pg.Tx, query url.Values, cursor string, size uint) (any, error)
+	// List deleted and archived rows of the model
+	ListTrashed func(ctx context.Context, tx pg.Tx) (any, error)
+	// Columns that can be edited
+	Editable []string
+	// Permission required to edit the model directly
+	Permission string
+}
+
+// Page: Query of a catalog list, along with its filters
+type Page struct {
+	Cursor string `query:"cursor"`
+	Size   uint   `query:"size" validate:"max=100"`
+}
+
+// Entities: Catalog models, by table name
+var Entities = map[string]Entity{
+	Movie{}.TableName(): {
+		Find:        find[Movie],
+		FindTrashed: findTrashed[Movie],
+		List:        list[Movie],
+		ListTrashed: listTrashed[Movie],
+		Editable:    []string{"title", "original_title", "overview", "release_date", "runtime"},
+		Permission:  rbac.PermMovieWrite,
+	},
+	Person{}.TableName(): {
+		Find:        find[Person],
+		FindTrashed: findTrashed[Person],
+		List:        list[Person],
+		ListTrashed: listTrashed[Person],
+		Editable:    []string{"name", "biography", "birth_date", "death_date"},
+		Permission:  rbac.PermPersonWrite,
+	},
+}
+
+func find[M sql.Table, PM interface {
+	*M
+	Model
+}](ctx context.Context, tx pg.Tx, id pgtype.UUID) (Model, error) {
+	item, err := sql.Read[M]().Where(sql.I("id").Eq(id)).FindOne(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return PM(item), nil
+}
+
+func findTrashed[M sql.Table, PM interface {
+	*M
+	Model
+}](ctx context.Context, tx pg.Tx, id pgtype.UUID) (Model, error) {
+	item, err := sql.Read[M]().
+		WithDeleted().
+		WithArchived().
+		Where(sql.I("id").Eq(id), trashed).
+		FindOne(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return PM(item), nil
+}
+
+func list[M sql.Listable](ctx context.Context, tx pg.Tx, query url.Values, cursor string, size uint) (any, error) {
+	list, err := sql.ParseList[M](query)
+	if err != nil {
+		return nil, err
+	}
+	page, err := sql.Read[M]().List(list).Paginate(ctx, tx, cursor, size, list.Sort...)
+	if err != nil {
+		return nil, err
+	}
+	return page.Project(list.Fields)
+}
+
+func listTrashed[M sql.Table](ctx context.Context, tx pg.Tx) (any, error) {
+	return sql.Read[M]().
+		WithDeleted().
+		WithArchived().
+		Where(trashed).
+		Order(sql.COALESCE(sql.I("deleted_at"), sql.I("archived_at")).Desc()).
+		FindAll(ctx, tx)
+}
+
+var trashed = sql.Or(
+	sql.I("deleted_at").IsNotNull(),
+	sql.I("archived_at").IsNotNull(),
+)
@@ -0,0 +1,32 @@
+package model
+
+import (
+	sql "movies/utils/sql"
+
+	pgtype "github.com/jackc/pgtype"
+)
+
+type Movie struct {
+	sql.Extended
+	sql.Archived
+	sql.Revisioned
+	Title         string      `json:"title" db:"title" validate:"required,max=255" mod:"nfc,collapse,quotes"`
+	OriginalTitle string      `json:"original_title" db:"original_title" validate:"max=255" mod:"nfc,collapse,quotes"`
+	Overview      string      `json:"overview" db:"overview" mod:"nfc,trim,quotes"`
+	ReleaseDate   pgtype.Date `json:"release_date" db:"release_date" validate:"omitempty,release_year"`
+	Runtime       pgtype.Int4 `json:"runtime" db:"runtime" validate:"omitempty,runtime"`
+
+	TitleUnaccented string `json:"-" db:"title_unaccented" mod:"unaccent=Title"`
+}
+
+func (Movie) TableName() string { return "movies" }
+
+var movieListing = sql.NewListSpec().
+	Filter("title", sql.OpEq, sql.OpContains).
+	Filter("original_title", sql.OpEq, sql.OpContains).
+	Filter("release_date", sql.OpEq, sql.OpGte, sql.OpLte, sql.OpNull).
+	Filter("runtime", sql.OpGte, sql.OpLte, sql.OpNull).
+	Sort("title", "created_at").
+	Field("id", "title", "original_title", "overview", "release_date", "runtime", "created_at")
+
+func (Movie) Listing() *sql.ListSpec { return movieListing }
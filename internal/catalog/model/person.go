@@ -0,0 +1,30 @@
+package model
+
+import (
+	sql "movies/utils/sql"
+
+	pgtype "github.com/jackc/pgtype"
+)
+
+type Person struct {
+	sql.Extended
+	sql.Archived
+	sql.Revisioned
+	Name      string      `json:"name" db:"name" validate:"required,max=255" mod:"nfc,collapse,quotes"`
+	Biography string      `json:"biography" db:"biography" mod:"nfc,trim,quotes"`
+	BirthDate pgtype.Date `json:"birth_date" db:"birth_date"`
+	DeathDate pgtype.Date `json:"death_date" db:"death_date"`
+
+	NameUnaccented string `json:"-" db:"name_unaccented" mod:"unaccent=Name"`
+}
+
+func (Person) TableName() string { return "people" }
+
+var personListing = sql.NewListSpec().
+	Filter("name", sql.OpEq, sql.OpContains).
+	Filter("birth_date", sql.OpEq, sql.OpGte, sql.OpLte, sql.OpNull).
+	Filter("death_date", sql.OpEq, sql.OpGte, sql.OpLte, sql.OpNull).
+	Sort("name", "created_at").
+	Field("id", "name", "biography", "birth_date", "death_date", "created_at")
+
+func (Person) Listing() *sql.ListSpec { return personListing }
@@ -0,0 +1,120 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+
+	middleware "movies/internal/auth/middleware"
+	auth "movies/internal/auth/model"
+	catalog "movies/internal/catalog/model"
+	cerrors "movies/utils/cerrors"
+	form "movies/utils/form"
+	pg "movies/utils/pg"
+	render "movies/utils/render"
+	sql "movies/utils/sql"
+
+	mux "github.com/gorilla/mux"
+	pgtype "github.com/jackc/pgtype"
+)
+
+type CatalogRouter struct {
+	router *mux.Router
+}
+
+func NewCatalogRouter(r *mux.Router) *CatalogRouter {
+	return &CatalogRouter{router: r}
+}
+
+func (obj *CatalogRouter) Handle() {
+	s := obj.router.PathPrefix("/catalog/{entity}").Subrouter()
+	s.HandleFunc("", render.Handler(obj.list)).Methods(http.MethodGet)
+	s.HandleFunc("/{id}", render.Handler(obj.patch)).Methods(http.MethodPatch)
+}
+
+// list: Page through live catalog rows, with `filter[...]`, `sort` and `fields`
+func (obj *CatalogRouter) list(w http.ResponseWriter, r *http.Request) error {
+	entity, err := parseEntity(r)
+	if err != nil {
+		return err
+	}
+	if !middleware.HasScope(w, r, auth.ScopeCatalogRead) {
+		return nil
+	}
+	query := catalog.Page{}
+	if err := form.ValidateQuery(r, &query); err != nil {
+		return err
+	}
+
+	page, err := entity.List(r.Context(), pg.EmptyTx(), r.URL.Query(), query.Cursor, query.Size)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, page)
+	return nil
+}
+
+// patch: Edit a catalog row directly with a merge-patch body
+func (obj *CatalogRouter) patch(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	entity, err := parseEntity(r)
+	if err != nil {
+		return err
+	}
+	if !middleware.Can(w, r, entity.Permission) || !middleware.HasScope(w, r, auth.ScopeCatalogWrite) {
+		return nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != sql.MergePatchContentType && mediaType != "application/json" {
+		return render.NewStatus(http.StatusUnsupportedMediaType, cerrors.NewString("Content-Type must be `%s`", sql.MergePatchContentType))
+	}
+	id, err := parseID(r)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	item, err := entity.Find(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	record, err := sql.DecodeMergePatch(ctx, r.Body, item, entity.Editable)
+	if err != nil {
+		return err
+	}
+	if err := sql.UpdateByPK(ctx, tx, item, true, record); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, item)
+	return nil
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+func parseEntity(r *http.Request) (catalog.Entity, error) {
+	name := mux.Vars(r)["entity"]
+	entity, ok := catalog.Entities[name]
+	if !ok {
+		return entity, cerrors.NewValidation("oneof", "entity", "`"+name+"` is not a catalog entity", name)
+	}
+	return entity, nil
+}
+
+func parseID(r *http.Request) (pgtype.UUID, error) {
+	value := mux.Vars(r)["id"]
+	id, err := pg.ParseUUID(value)
+	if err != nil {
+		return id, cerrors.NewValidation("uuid", "id", "`"+value+"` is not a valid UUID", value)
+	}
+	return id, nil
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	pgtype "github.com/jackc/pgtype"
+)
+
+/*============================================================================*/
+/*=====*                             Client                             *=====*/
+/*============================================================================*/
+
+// Client: Third-party application registered by a user
+//
+// Public clients, such as mobile or single-page apps, have no secret.
+type Client struct {
+	ID               pgtype.UUID        `json:"id" db:"id"`
+	ClientID         string             `json:"client_id" db:"client_id"`
+	ClientSecretHash []byte             `json:"-" db:"client_secret_hash"`
+	Name             string             `json:"name" db:"name"`
+	RedirectURIs     []string           `json:"redirect_uris" db:"redirect_uris"`
+	Scopes           []string           `json:"scopes" db:"scopes"`
+	OwnerID          pgtype.UUID        `json:"owner_id" db:"owner_id"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at" db:"created_at"`
+}
+
+func (Client) TableName() string { return "oauth_clients" }
+
+func (obj Client) GetPK() pgtype.UUID { return obj.ID }
+
+func (obj Client) IsConfidential() bool { return obj.ClientSecretHash != nil }
+
+// CreatedClient: New client with its secret, only shown once
+type CreatedClient struct {
+	*Client
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// Consent: Scopes a user granted to a client
+type Consent struct {
+	UserID    pgtype.UUID        `json:"user_id" db:"user_id"`
+	ClientID  pgtype.UUID        `json:"client_id" db:"client_id"`
+	Scopes    []string           `json:"scopes" db:"scopes"`
+	CreatedAt pgtype.Timestamptz `json:"created_at" db:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at" db:"updated_at"`
+}
+
+func (Consent) TableName() string { return "oauth_consents" }
+
+/*============================================================================*/
+/*=====*                             Tokens                             *=====*/
+/*============================================================================*/
+
+// Code: Authorization code, exchanged once with its PKCE verifier
+type Code struct {
+	ID                  pgtype.UUID        `json:"id" db:"id"`
+	CodeHash            []byte             `json:"-" db:"code_hash"`
+	ClientID            pgtype.UUID        `json:"client_id" db:"client_id"`
+	UserID              pgtype.UUID        `json:"user_id" db:"user_id"`
+	RedirectURI         string             `json:"redirect_uri" db:"redirect_uri"`
+	Scopes              []string           `json:"scopes" db:"scopes"`
+	CodeChallenge       string             `json:"-" db:"code_challenge"`
+	CodeChallengeMethod string             `json:"-" db:"code_challenge_method"`
+	ExpiresAt           pgtype.Timestamptz `json:"expires_at" db:"expires_at"`
+	UsedAt              pgtype.Timestamptz `json:"used_at" db:"used_at"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at" db:"created_at"`
+}
+
+func (Code) TableName() string { return "oauth_codes" }
+
+type Kind string
+
+const (
+	KindAccess  Kind = "access"
+	KindRefresh Kind = "refresh"
+)
+
+// Token: Opaque access or refresh token
+//
+// Tokens issued from the same code share a family, revoked as a whole when a
+// code or refresh token is replayed.
+type Token struct {
+	ID        pgtype.UUID        `json:"id" db:"id"`
+	TokenHash []byte             `json:"-" db:"token_hash"`
+	Kind      Kind               `json:"kind" db:"kind"`
+	FamilyID  pgtype.UUID        `json:"family_id" db:"family_id"`
+	ClientID  pgtype.UUID        `json:"client_id" db:"client_id"`
+	UserID    pgtype.UUID        `json:"user_id" db:"user_id"`
+	Scopes    []string           `json:"scopes" db:"scopes"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at" db:"expires_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at" db:"used_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at" db:"revoked_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at" db:"created_at"`
+}
+
+func (Token) TableName() string { return "oauth_tokens" }
+
+func (obj Token) GetPK() pgtype.UUID { return obj.ID }
+
+/*============================================================================*/
+/*=====*                           Responses                            *=====*/
+/*============================================================================*/
+
+// TokenResponse: Successful token response (RFC 6749 5.1)
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// Introspection: Token introspection response (RFC 7662)
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+}
+
+/*============================================================================*/
+/*=====*                             Forms                              *=====*/
+/*============================================================================*/
+
+// CreateClient: Body of a client registration
+type CreateClient struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" validate:"required,min=1,max=10,unique,dive,url,max=2048"`
+	Scopes       []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=catalog:read catalog:write suggestions:write profile:read profile:write lists:read lists:write"`
+	Confidential bool     `json:"confidential"`
+}
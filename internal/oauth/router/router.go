@@ -0,0 +1,355 @@
+package router
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	middleware "movies/internal/auth/middleware"
+	model "movies/internal/oauth/model"
+	server "movies/internal/oauth/server"
+	cerrors "movies/utils/cerrors"
+	config "movies/utils/config"
+	form "movies/utils/form"
+	pg "movies/utils/pg"
+	principal "movies/utils/principal"
+	render "movies/utils/render"
+
+	mux "github.com/gorilla/mux"
+)
+
+//go:embed templates/consent.html
+var files embed.FS
+
+var consentPage = template.Must(template.ParseFS(files, "templates/consent.html"))
+
+// Descriptions of the scopes on the consent screen
+var scopeDescriptions = map[string]string{
+	"catalog:read":      "Read the catalog and its edit history",
+	"catalog:write":     "Edit the catalog on your behalf",
+	"suggestions:write": "Propose catalog edits on your behalf",
+	"profile:read":      "Read your profile and settings",
+	"profile:write":     "Change your profile and settings",
+	"lists:read":        "Read your lists",
+	"lists:write":       "Change your lists",
+}
+
+// Parameters of an authorization request carried by the consent form
+var authorizeParams = []string{"response_type", "client_id", "redirect_uri", "scope", "state", "code_challenge", "code_challenge_method"}
+
+type OAuthRouter struct {
+	router *mux.Router
+}
+
+func NewOAuthRouter(r *mux.Router) *OAuthRouter {
+	return &OAuthRouter{router: r}
+}
+
+func (obj *OAuthRouter) Handle() {
+	s := obj.router.PathPrefix("/oauth").Subrouter()
+	s.HandleFunc("/clients", render.Handler(obj.listClients)).Methods(http.MethodGet)
+	s.HandleFunc("/clients", render.Handler(obj.createClient)).Methods(http.MethodPost)
+	s.HandleFunc("/clients/{id}", render.Handler(obj.deleteClient)).Methods(http.MethodDelete)
+
+	s.HandleFunc("/authorize", handler(obj.authorize)).Methods(http.MethodGet)
+	s.HandleFunc("/authorize", handler(obj.decide)).Methods(http.MethodPost)
+	s.HandleFunc("/token", handler(obj.token)).Methods(http.MethodPost)
+	s.HandleFunc("/revoke", handler(obj.revoke)).Methods(http.MethodPost)
+	s.HandleFunc("/introspect", handler(obj.introspect)).Methods(http.MethodPost)
+}
+
+/*============================================================================*/
+/*=====*                            Clients                             *=====*/
+/*============================================================================*/
+
+// listClients: Clients registered by the current user
+func (obj *OAuthRouter) listClients(w http.ResponseWriter, r *http.Request) error {
+	if !middleware.Unscoped(w, r) {
+		return nil
+	}
+	p, _ := principal.FromContext(r.Context())
+
+	clients, err := server.ListClients(r.Context(), pg.EmptyTx(), p.UserID)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, clients)
+	return nil
+}
+
+// createClient: Register a client, its secret is only shown once
+func (obj *OAuthRouter) createClient(w http.ResponseWriter, r *http.Request) error {
+	if !middleware.Unscoped(w, r) {
+		return nil
+	}
+	p, _ := principal.FromContext(r.Context())
+
+	var body model.CreateClient
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+
+	client, err := server.RegisterClient(r.Context(), pg.EmptyTx(), p.UserID, body)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusCreated, client)
+	return nil
+}
+
+// deleteClient: Remove a client, revoking its tokens
+func (obj *OAuthRouter) deleteClient(w http.ResponseWriter, r *http.Request) error {
+	if !middleware.Unscoped(w, r) {
+		return nil
+	}
+	p, _ := principal.FromContext(r.Context())
+
+	value := mux.Vars(r)["id"]
+	id, err := pg.ParseUUID(value)
+	if err != nil {
+		return cerrors.NewValidation("uuid", "id", "`"+value+"` is not a valid UUID", value)
+	}
+
+	if err := server.DeleteClient(r.Context(), pg.EmptyTx(), p.UserID, id); err != nil {
+		return err
+	}
+	render.NoContent(w)
+	return nil
+}
+
+/*============================================================================*/
+/*=====*                         Authorization                          *=====*/
+/*============================================================================*/
+
+// authorize: Show the consent screen, or redirect at once when the user
+// already granted the scopes
+func (obj *OAuthRouter) authorize(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	auth, err := parseAuthorization(w, r, r.URL.Query())
+	if auth == nil {
+		return err
+	}
+
+	u, ok := middleware.CurrentUser(ctx)
+	if !ok {
+		// The front-end signs the user in, then comes back here
+		login := config.OAuth().LoginURL() + "?" + url.Values{"next": {r.URL.RequestURI()}}.Encode()
+		http.Redirect(w, r, login, http.StatusFound)
+		return nil
+	}
+	if p, _ := principal.FromContext(ctx); p.Scoped() {
+		return render.ErrForbidden
+	}
+
+	if r.URL.Query().Get("prompt") != "consent" {
+		consented, err := server.HasConsent(ctx, pg.EmptyTx(), u.ID, auth)
+		if err != nil {
+			return err
+		}
+		if consented {
+			return approve(w, r, auth)
+		}
+	}
+
+	params := map[string]string{}
+	for _, name := range authorizeParams {
+		params[name] = r.URL.Query().Get(name)
+	}
+	scopes := make([]string, len(auth.Scopes))
+	for i, scope := range auth.Scopes {
+		scopes[i] = scopeDescriptions[scope]
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+	w.Header().Set("Cache-Control", "no-store")
+	return consentPage.Execute(w, map[string]any{
+		"Client":       auth.Client.Name,
+		"Username":     u.Username,
+		"Scopes":       scopes,
+		"RedirectURI":  auth.RedirectURI,
+		"Params":       params,
+		"ConsentToken": server.ConsentToken(u.ID, auth),
+	})
+}
+
+// decide: Submission of the consent screen
+func (obj *OAuthRouter) decide(w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return render.NewStatus(http.StatusBadRequest, cerrors.NewString("Invalid form"))
+	}
+	auth, err := parseAuthorization(w, r, r.PostForm)
+	if auth == nil {
+		return err
+	}
+
+	u, ok := middleware.CurrentUser(r.Context())
+	if !ok {
+		return render.ErrUnauthorized
+	}
+	if !server.VerifyConsentToken(u.ID, auth, r.PostForm.Get("consent_token")) {
+		return render.ErrForbidden
+	}
+
+	if r.PostForm.Get("decision") != "approve" {
+		http.Redirect(w, r, server.Deny(auth), http.StatusFound)
+		return nil
+	}
+	return approve(w, r, auth)
+}
+
+/*============================================================================*/
+/*=====*                            Tokens                              *=====*/
+/*============================================================================*/
+
+// token: Token endpoint, for the authorization_code and refresh_token grants
+func (obj *OAuthRouter) token(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	client, err := authenticateClient(r)
+	if err != nil {
+		return err
+	}
+
+	var response *model.TokenResponse
+	switch grantType := r.PostForm.Get("grant_type"); grantType {
+	case "authorization_code":
+		response, err = server.Exchange(ctx, client,
+			r.PostForm.Get("code"), r.PostForm.Get("redirect_uri"), r.PostForm.Get("code_verifier"))
+	case "refresh_token":
+		response, err = server.Refresh(ctx, client,
+			r.PostForm.Get("refresh_token"), strings.Fields(r.PostForm.Get("scope")))
+	default:
+		err = server.ErrUnsupportedGrantType("Unsupported grant_type `" + grantType + "`")
+	}
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, http.StatusOK, response)
+	return nil
+}
+
+// revoke: Token revocation (RFC 7009)
+func (obj *OAuthRouter) revoke(w http.ResponseWriter, r *http.Request) error {
+	client, err := authenticateClient(r)
+	if err != nil {
+		return err
+	}
+
+	if err := server.Revoke(r.Context(), pg.EmptyTx(), client, r.PostForm.Get("token")); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// introspect: Token introspection (RFC 7662)
+func (obj *OAuthRouter) introspect(w http.ResponseWriter, r *http.Request) error {
+	client, err := authenticateClient(r)
+	if err != nil {
+		return err
+	}
+
+	introspection, err := server.Introspect(r.Context(), pg.EmptyTx(), client, r.PostForm.Get("token"))
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, http.StatusOK, introspection)
+	return nil
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+// parseAuthorization: Validate an authorization request
+//
+// Errors are shown to the user until the redirect URI is trusted, and sent
+// back to the client afterwards: the redirect is written and no authorization
+// nor error is returned.
+func parseAuthorization(w http.ResponseWriter, r *http.Request, values url.Values) (*server.Authorization, error) {
+	auth, redirect, err := server.ParseAuthorization(r.Context(), pg.EmptyTx(), values)
+	if err != nil {
+		return nil, err
+	} else if redirect != "" {
+		http.Redirect(w, r, redirect, http.StatusFound)
+		return nil, nil
+	}
+	return auth, nil
+}
+
+func approve(w http.ResponseWriter, r *http.Request, auth *server.Authorization) error {
+	ctx := r.Context()
+	u, _ := middleware.CurrentUser(ctx)
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	redirect, err := server.Approve(ctx, tx, u.ID, auth)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	http.Redirect(w, r, redirect, http.StatusFound)
+	return nil
+}
+
+// authenticateClient: Client of a form request, with HTTP Basic credentials or
+// `client_id` and `client_secret` parameters
+func authenticateClient(r *http.Request) (*model.Client, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, server.ErrInvalidRequest("Invalid form")
+	}
+
+	clientID, secret, ok := r.BasicAuth()
+	if ok {
+		// RFC 6749 2.3.1: credentials are form-encoded before Basic encoding
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	return server.AuthenticateClient(r.Context(), pg.EmptyTx(), clientID, secret)
+}
+
+// handler: Adapt a handler of the OAuth2 endpoints, rendering returned errors
+// in the OAuth2 format
+func handler(fn render.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			renderError(w, r, err)
+		}
+	}
+}
+
+// renderError: OAuth2 error response, other errors go through render
+func renderError(w http.ResponseWriter, r *http.Request, err error) {
+	var oauthErr *server.Error
+	if !errors.As(err, &oauthErr) {
+		render.Error(w, r, err)
+		return
+	}
+
+	if oauthErr.Status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(oauthErr.Status)
+	_ = json.NewEncoder(w).Encode(oauthErr)
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	onetime "movies/internal/auth/onetime"
+	token "movies/internal/auth/token"
+	model "movies/internal/oauth/model"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	goqu "github.com/doug-martin/goqu/v9"
+	pgtype "github.com/jackc/pgtype"
+	lo "github.com/samber/lo"
+)
+
+// Lifetime of an authorization code
+const codeTTL = 10 * time.Minute
+
+// Only SHA-256 challenges are accepted, `plain` offers no protection
+const challengeMethod = "S256"
+
+// Authorization: Validated authorization request
+type Authorization struct {
+	Client        *model.Client
+	RedirectURI   string
+	Scopes        []string
+	State         string
+	CodeChallenge string
+}
+
+// Scope: Scopes as a space-separated string
+func (obj Authorization) Scope() string { return strings.Join(obj.Scopes, " ") }
+
+// ParseAuthorization: Validate the parameters of an authorization request
+//
+// Until the client and redirect URI are known the error must be shown to the
+// user. Afterwards it is returned with a redirect URI, to send it back to the
+// client.
+func ParseAuthorization(ctx context.Context, tx pg.Tx, query url.Values) (*Authorization, string, error) {
+	client, err := FindClient(ctx, tx, query.Get("client_id"))
+	if pg.IsNotFound(err) {
+		return nil, "", ErrInvalidRequest("Unknown client_id")
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	redirectURI := query.Get("redirect_uri")
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !lo.Contains(client.RedirectURIs, redirectURI) {
+		return nil, "", ErrInvalidRequest("redirect_uri is not registered for this client")
+	}
+
+	auth := &Authorization{
+		Client:        client,
+		RedirectURI:   redirectURI,
+		State:         query.Get("state"),
+		CodeChallenge: query.Get("code_challenge"),
+	}
+	switch {
+	case query.Get("response_type") != "code":
+		return nil, auth.errorRedirect(ErrUnsupportedResponseType("Only the code response type is supported")), nil
+	case auth.CodeChallenge == "":
+		return nil, auth.errorRedirect(ErrInvalidRequest("code_challenge is required")), nil
+	case query.Get("code_challenge_method") != challengeMethod:
+		return nil, auth.errorRedirect(ErrInvalidRequest("code_challenge_method must be S256")), nil
+	}
+
+	auth.Scopes = strings.Fields(query.Get("scope"))
+	if len(auth.Scopes) == 0 {
+		auth.Scopes = client.Scopes
+	}
+	if extra, _ := lo.Difference(auth.Scopes, client.Scopes); len(extra) > 0 {
+		return nil, auth.errorRedirect(ErrInvalidScope("Scopes not allowed for this client: " + strings.Join(extra, " "))), nil
+	}
+	auth.Scopes = lo.Uniq(auth.Scopes)
+	return auth, "", nil
+}
+
+// HasConsent: Whether the user already granted every requested scope
+func HasConsent(ctx context.Context, tx pg.Tx, userID pgtype.UUID, auth *Authorization) (bool, error) {
+	consent, err := sql.Read[model.Consent]().
+		Where(sql.I("user_id").Eq(userID), sql.I("client_id").Eq(auth.Client.ID)).
+		FindOne(ctx, tx)
+	if pg.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return lo.Every(consent.Scopes, auth.Scopes), nil
+}
+
+// ConsentToken: Signature binding a consent form to a user and its request
+func ConsentToken(userID pgtype.UUID, auth *Authorization) string {
+	return onetime.Sign(consentPayload(userID, auth))
+}
+
+// VerifyConsentToken: Check the signature of a consent form
+func VerifyConsentToken(userID pgtype.UUID, auth *Authorization, value string) bool {
+	return onetime.Verify(consentPayload(userID, auth), value)
+}
+
+// Approve: Record the consent and redirect to the client with a code
+func Approve(ctx context.Context, tx pg.Tx, userID pgtype.UUID, auth *Authorization) (string, error) {
+	scopes, err := pg.PrimitiveToTextArray(auth.Scopes)
+	if err != nil {
+		return "", err
+	}
+
+	// Grants accumulate, a client asking for less keeps what was granted
+	query, args, err := pg.SQLBuilder().
+		Insert(model.Consent{}.TableName()).
+		Rows(sql.Record{"user_id": userID, "client_id": auth.Client.ID, "scopes": scopes}).
+		OnConflict(goqu.DoUpdate("user_id, client_id", sql.Record{
+			"scopes":     sql.L(`ARRAY(SELECT DISTINCT unnest("oauth_consents"."scopes" || EXCLUDED."scopes"))`),
+			"updated_at": sql.NOW,
+		})).
+		ToSQL()
+	if err != nil {
+		return "", err
+	}
+	if _, err := pg.Client(tx).Exec(ctx, query, args...); err != nil {
+		return "", err
+	}
+
+	code, err := token.New()
+	if err != nil {
+		return "", err
+	}
+	if err := sql.Create(ctx, tx, &model.Code{}, sql.Record{
+		"code_hash":             token.Hash(code),
+		"client_id":             auth.Client.ID,
+		"user_id":               userID,
+		"redirect_uri":          auth.RedirectURI,
+		"scopes":                scopes,
+		"code_challenge":        auth.CodeChallenge,
+		"code_challenge_method": challengeMethod,
+		"expires_at":            time.Now().Add(codeTTL),
+	}); err != nil {
+		return "", err
+	}
+	return auth.redirect(url.Values{"code": {code}}), nil
+}
+
+// Deny: Redirect to the client with an access denied error
+func Deny(auth *Authorization) string {
+	return auth.errorRedirect(ErrAccessDenied("The user denied the request"))
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+func consentPayload(userID pgtype.UUID, auth *Authorization) string {
+	return strings.Join([]string{
+		"consent",
+		pg.FormatUUID(userID),
+		auth.Client.ClientID,
+		auth.RedirectURI,
+		auth.Scope(),
+		auth.State,
+		auth.CodeChallenge,
+	}, "\n")
+}
+
+func (obj Authorization) errorRedirect(err *Error) string {
+	values := url.Values{"error": {err.Code}}
+	if err.Description != "" {
+		values.Set("error_description", err.Description)
+	}
+	return obj.redirect(values)
+}
+
+// redirect: Redirect URI with response parameters and the state
+func (obj Authorization) redirect(values url.Values) string {
+	if obj.State != "" {
+		values.Set("state", obj.State)
+	}
+	u, err := url.Parse(obj.RedirectURI)
+	if err != nil {
+		return obj.RedirectURI
+	}
+	query := u.Query()
+	for key := range values {
+		query.Set(key, values.Get(key))
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
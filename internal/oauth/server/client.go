@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+
+	token "movies/internal/auth/token"
+	model "movies/internal/oauth/model"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	pgtype "github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+)
+
+// RegisterClient: Create a client, its secret is only returned here
+func RegisterClient(ctx context.Context, tx pg.Tx, ownerID pgtype.UUID, body model.CreateClient) (*model.CreatedClient, error) {
+	clientID, err := token.New()
+	if err != nil {
+		return nil, err
+	}
+	redirectURIs, err := pg.PrimitiveToTextArray(body.RedirectURIs)
+	if err != nil {
+		return nil, err
+	}
+	scopes, err := pg.PrimitiveToTextArray(body.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	record := sql.Record{
+		"client_id":     clientID,
+		"name":          body.Name,
+		"redirect_uris": redirectURIs,
+		"scopes":        scopes,
+		"owner_id":      ownerID,
+	}
+	var secret string
+	if body.Confidential {
+		if secret, err = token.New(); err != nil {
+			return nil, err
+		}
+		record["client_secret_hash"] = token.Hash(secret)
+	}
+
+	client := &model.Client{}
+	if err := sql.Create(ctx, tx, client, record); err != nil {
+		return nil, err
+	}
+	return &model.CreatedClient{Client: client, ClientSecret: secret}, nil
+}
+
+// ListClients: Clients registered by a user
+func ListClients(ctx context.Context, tx pg.Tx, ownerID pgtype.UUID) ([]*model.Client, error) {
+	return sql.Read[model.Client]().
+		Where(sql.I("owner_id").Eq(ownerID)).
+		Order(sql.I("created_at").Desc()).
+		FindAll(ctx, tx)
+}
+
+// DeleteClient: Remove a client of a user, with its codes and tokens
+func DeleteClient(ctx context.Context, tx pg.Tx, ownerID, id pgtype.UUID) error {
+	count, err := sql.HardDelete(ctx, tx, model.Client{}, sql.And(
+		sql.I("id").Eq(id),
+		sql.I("owner_id").Eq(ownerID),
+	))
+	if err == nil && count == 0 {
+		return pgx.ErrNoRows
+	}
+	return err
+}
+
+// FindClient: Get a client by its public identifier
+func FindClient(ctx context.Context, tx pg.Tx, clientID string) (*model.Client, error) {
+	return sql.Read[model.Client]().Where(sql.I("client_id").Eq(clientID)).FindOne(ctx, tx)
+}
+
+// AuthenticateClient: Check the credentials of a client
+//
+// Confidential clients must present their secret, public clients none.
+func AuthenticateClient(ctx context.Context, tx pg.Tx, clientID, secret string) (*model.Client, error) {
+	if clientID == "" {
+		return nil, ErrInvalidClient("Missing client_id")
+	}
+	client, err := FindClient(ctx, tx, clientID)
+	if pg.IsNotFound(err) {
+		return nil, ErrInvalidClient("Unknown client")
+	} else if err != nil {
+		return nil, err
+	}
+
+	if client.IsConfidential() {
+		if secret == "" || subtle.ConstantTimeCompare(token.Hash(secret), client.ClientSecretHash) != 1 {
+			return nil, ErrInvalidClient("Invalid client credentials")
+		}
+	} else if secret != "" {
+		return nil, ErrInvalidClient("Public clients have no secret")
+	}
+	return client, nil
+}
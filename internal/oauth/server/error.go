@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+)
+
+// Error: OAuth2 error response (RFC 6749 4.1.2.1 and 5.2)
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	Status      int    `json:"-"`
+}
+
+func (e *Error) Error() string { return e.Code + ": " + e.Description }
+
+func newError(status int, code, description string) *Error {
+	return &Error{Code: code, Description: description, Status: status}
+}
+
+func ErrInvalidRequest(description string) *Error {
+	return newError(http.StatusBadRequest, "invalid_request", description)
+}
+
+func ErrInvalidClient(description string) *Error {
+	return newError(http.StatusUnauthorized, "invalid_client", description)
+}
+
+func ErrInvalidGrant(description string) *Error {
+	return newError(http.StatusBadRequest, "invalid_grant", description)
+}
+
+func ErrInvalidScope(description string) *Error {
+	return newError(http.StatusBadRequest, "invalid_scope", description)
+}
+
+func ErrUnsupportedGrantType(description string) *Error {
+	return newError(http.StatusBadRequest, "unsupported_grant_type", description)
+}
+
+func ErrUnsupportedResponseType(description string) *Error {
+	return newError(http.StatusBadRequest, "unsupported_response_type", description)
+}
+
+func ErrAccessDenied(description string) *Error {
+	return newError(http.StatusForbidden, "access_denied", description)
+}
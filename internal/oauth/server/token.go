@@ -0,0 +1,300 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	token "movies/internal/auth/token"
+	model "movies/internal/oauth/model"
+	user "movies/internal/user/model"
+	config "movies/utils/config"
+	logger "movies/utils/logger"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	errors "emperror.dev/errors"
+	pgtype "github.com/jackc/pgtype"
+	lo "github.com/samber/lo"
+)
+
+// Prefixes of the tokens, telling them apart from other bearer tokens
+const (
+	AccessPrefix  = "mvo_"
+	RefreshPrefix = "mvr_"
+)
+
+var ErrInvalid = errors.New("invalid OAuth access token")
+
+// Is: Whether a bearer token is an OAuth access token
+func Is(bearer string) bool {
+	return strings.HasPrefix(bearer, AccessPrefix)
+}
+
+/*============================================================================*/
+/*=====*                             Grants                             *=====*/
+/*============================================================================*/
+
+// Exchange: Redeem an authorization code with its PKCE verifier
+//
+// A code can be redeemed once. Replaying it revokes the tokens it issued. The
+// exchange runs in its own transaction so the revocation survives the error.
+func Exchange(ctx context.Context, client *model.Client, plain, redirectURI, verifier string) (*model.TokenResponse, error) {
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := sql.Read[model.Code]().
+		Where(sql.I("code_hash").Eq(token.Hash(plain))).
+		ForUpdate().
+		FindOne(ctx, tx)
+	if pg.IsNotFound(err) {
+		return nil, ErrInvalidGrant("Invalid authorization code")
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case code.UsedAt.Status == pgtype.Present:
+		logger.With("client_id", client.ClientID).Warn(ctx, "Authorization code replayed, tokens revoked")
+		if err := revokeFamily(ctx, tx, code.ID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidGrant("Authorization code already used")
+	case code.ExpiresAt.Time.Before(time.Now()):
+		return nil, ErrInvalidGrant("Authorization code expired")
+	case code.ClientID != client.ID:
+		return nil, ErrInvalidGrant("Authorization code issued to another client")
+	case code.RedirectURI != redirectURI:
+		return nil, ErrInvalidGrant("redirect_uri does not match the authorization request")
+	case !verifyChallenge(verifier, code.CodeChallenge):
+		return nil, ErrInvalidGrant("Invalid code_verifier")
+	}
+
+	if err := sql.Update(ctx, tx, code, false, sql.Record{"used_at": sql.NOW}, sql.I("id").Eq(code.ID)); err != nil {
+		return nil, err
+	}
+	response, err := issue(ctx, tx, client, code.UserID, code.Scopes, code.ID)
+	if err != nil {
+		return nil, err
+	}
+	return response, tx.Commit(ctx)
+}
+
+// Refresh: Rotate a refresh token, optionally narrowing its scopes
+//
+// Presenting a rotated refresh token again revokes its whole family, in its
+// own transaction like an exchange.
+func Refresh(ctx context.Context, client *model.Client, plain string, scopes []string) (*model.TokenResponse, error) {
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := findToken(ctx, tx, plain, model.KindRefresh, true)
+	if errors.Is(err, ErrInvalid) {
+		return nil, ErrInvalidGrant("Invalid refresh token")
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case current.ClientID != client.ID:
+		return nil, ErrInvalidGrant("Refresh token issued to another client")
+	case current.RevokedAt.Status == pgtype.Present:
+		return nil, ErrInvalidGrant("Refresh token revoked")
+	case current.UsedAt.Status == pgtype.Present:
+		logger.With("client_id", client.ClientID).Warn(ctx, "OAuth refresh token reused, family revoked")
+		if err := revokeFamily(ctx, tx, current.FamilyID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidGrant("Refresh token already used")
+	case current.ExpiresAt.Time.Before(time.Now()):
+		return nil, ErrInvalidGrant("Refresh token expired")
+	}
+
+	if len(scopes) == 0 {
+		scopes = current.Scopes
+	} else if extra, _ := lo.Difference(scopes, current.Scopes); len(extra) > 0 {
+		return nil, ErrInvalidScope("Scopes exceed the original grant: " + strings.Join(extra, " "))
+	}
+
+	if err := sql.Update(ctx, tx, current, false, sql.Record{"used_at": sql.NOW}, sql.I("id").Eq(current.ID)); err != nil {
+		return nil, err
+	}
+	response, err := issue(ctx, tx, client, current.UserID, scopes, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	return response, tx.Commit(ctx)
+}
+
+/*============================================================================*/
+/*=====*                       Revocation (RFC 7009)                    *=====*/
+/*============================================================================*/
+
+// Revoke: Revoke a token of a client, refresh tokens take their family along
+//
+// Unknown tokens are ignored, as the specification requires.
+func Revoke(ctx context.Context, tx pg.Tx, client *model.Client, plain string) error {
+	current, err := findToken(ctx, tx, plain, "", false)
+	if errors.Is(err, ErrInvalid) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if current.ClientID != client.ID {
+		return nil
+	}
+
+	if current.Kind == model.KindRefresh {
+		return revokeFamily(ctx, tx, current.FamilyID)
+	}
+	return sql.Update(ctx, tx, current, false, sql.Record{"revoked_at": sql.NOW}, sql.I("id").Eq(current.ID))
+}
+
+/*============================================================================*/
+/*=====*                     Introspection (RFC 7662)                   *=====*/
+/*============================================================================*/
+
+// Introspect: State of a token, only disclosed to the client it was issued to
+func Introspect(ctx context.Context, tx pg.Tx, client *model.Client, plain string) (*model.Introspection, error) {
+	current, err := findToken(ctx, tx, plain, "", false)
+	if errors.Is(err, ErrInvalid) {
+		return &model.Introspection{Active: false}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if current.ClientID != client.ID || !isLive(current) {
+		return &model.Introspection{Active: false}, nil
+	}
+
+	owner, err := sql.Read[user.User]().Where(sql.I("id").Eq(current.UserID)).FindOne(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Introspection{
+		Active:    true,
+		Scope:     strings.Join(current.Scopes, " "),
+		ClientID:  client.ClientID,
+		Username:  owner.Username,
+		TokenType: string(current.Kind) + "_token",
+		Exp:       current.ExpiresAt.Time.Unix(),
+		Iat:       current.CreatedAt.Time.Unix(),
+		Sub:       pg.FormatUUID(current.UserID),
+	}, nil
+}
+
+// Authenticate: Get the live access token of a bearer
+func Authenticate(ctx context.Context, tx pg.Tx, bearer string) (*model.Token, error) {
+	current, err := findToken(ctx, tx, bearer, model.KindAccess, false)
+	if err != nil {
+		return nil, err
+	}
+	if !isLive(current) {
+		return nil, ErrInvalid
+	}
+	return current, nil
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+// issue: Create an access and refresh token pair within a family
+func issue(ctx context.Context, tx pg.Tx, client *model.Client, userID pgtype.UUID, scopes []string, familyID pgtype.UUID) (*model.TokenResponse, error) {
+	array, err := pg.PrimitiveToTextArray(scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	plains := map[model.Kind]string{}
+	for kind, ttl := range map[model.Kind]time.Duration{
+		model.KindAccess:  config.Auth().OAuthAccessTTL(),
+		model.KindRefresh: config.Auth().RefreshTTL(),
+	} {
+		plain, err := token.New()
+		if err != nil {
+			return nil, err
+		}
+		plain = lo.Ternary(kind == model.KindAccess, AccessPrefix, RefreshPrefix) + plain
+
+		if err := sql.Create(ctx, tx, &model.Token{}, sql.Record{
+			"token_hash": token.Hash(plain),
+			"kind":       kind,
+			"family_id":  familyID,
+			"client_id":  client.ID,
+			"user_id":    userID,
+			"scopes":     array,
+			"expires_at": time.Now().Add(ttl),
+		}); err != nil {
+			return nil, err
+		}
+		plains[kind] = plain
+	}
+
+	return &model.TokenResponse{
+		AccessToken:  plains[model.KindAccess],
+		TokenType:    "Bearer",
+		ExpiresIn:    int(config.Auth().OAuthAccessTTL().Seconds()),
+		RefreshToken: plains[model.KindRefresh],
+		Scope:        strings.Join(scopes, " "),
+	}, nil
+}
+
+// findToken: Get a token by its plaintext, of a kind when given
+func findToken(ctx context.Context, tx pg.Tx, plain string, kind model.Kind, lock bool) (*model.Token, error) {
+	query := sql.Read[model.Token]().Where(sql.I("token_hash").Eq(token.Hash(plain)))
+	if kind != "" {
+		query = query.Where(sql.I("kind").Eq(kind))
+	}
+	if lock {
+		query = query.ForUpdate()
+	}
+	found, err := query.FindOne(ctx, tx)
+	if pg.IsNotFound(err) {
+		return nil, ErrInvalid
+	}
+	return found, err
+}
+
+func isLive(obj *model.Token) bool {
+	return obj.RevokedAt.Status != pgtype.Present &&
+		obj.UsedAt.Status != pgtype.Present &&
+		obj.ExpiresAt.Time.After(time.Now())
+}
+
+func revokeFamily(ctx context.Context, tx pg.Tx, familyID pgtype.UUID) error {
+	query, args, err := pg.SQLBuilder().
+		Update(model.Token{}.TableName()).
+		Set(sql.Record{"revoked_at": sql.NOW}).
+		Where(sql.I("family_id").Eq(familyID), sql.I("revoked_at").IsNull()).
+		ToSQL()
+	if err != nil {
+		return err
+	}
+	_, err = pg.Client(tx).Exec(ctx, query, args...)
+	return err
+}
+
+// verifyChallenge: PKCE S256 check, BASE64URL(SHA256(verifier)) == challenge
+func verifyChallenge(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
@@ -0,0 +1,174 @@
+package purge
+
+import (
+	"context"
+	"time"
+
+	catalog "movies/internal/catalog/model"
+	suggestion "movies/internal/suggestion/model"
+	config "movies/utils/config"
+	logger "movies/utils/logger"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	exp "github.com/doug-martin/goqu/v9/exp"
+	pgtype "github.com/jackc/pgtype"
+)
+
+/*============================================================================*/
+/*=====*                            Targets                             *=====*/
+/*============================================================================*/
+
+// Tables with soft-deleted rows, referencing tables first
+//
+// Users are not purged: deleted accounts are anonymized in place by the GDPR
+// erasure once their grace period is over, and the row is kept for the
+// suggestions, revisions and catalog rows attributed to it.
+var targets = []sql.Table{
+	suggestion.Suggestion{},
+	catalog.Movie{},
+	catalog.Person{},
+}
+
+/*============================================================================*/
+/*=====*                              Run                               *=====*/
+/*============================================================================*/
+
+// Report: Soft-deleted rows of a table past their retention
+type Report struct {
+	Table     string
+	Retention time.Duration
+	Cutoff    time.Time
+	Count     int64
+}
+
+// Run: Hard-delete soft-deleted rows past their retention
+//
+// With dryRun, rows are only counted.
+func Run(ctx context.Context, dryRun bool) ([]Report, error) {
+	reports := make([]Report, 0, len(targets))
+	for _, model := range targets {
+		table := model.TableName()
+		retention := config.Purge().Retention(table)
+		report := Report{Table: table, Retention: retention, Cutoff: time.Now().Add(-retention)}
+
+		var err error
+		if dryRun {
+			report.Count, err = count(ctx, model, report.Cutoff)
+		} else {
+			report.Count, err = remove(ctx, model, report.Cutoff, config.Purge().BatchSize())
+		}
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// Schedule: Run the purge at every interval until the context is done
+func Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reports, err := Run(ctx, false)
+			if err != nil {
+				logger.Error(ctx, "Purge failed: %v", err)
+			}
+			for _, report := range reports {
+				logger.Info(ctx, "Purged %d rows from `%s`", report.Count, report.Table)
+			}
+		}
+	}
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+func expired(model sql.Table, cutoff time.Time) exp.Expression {
+	table := model.TableName()
+	return sql.And(
+		sql.T(table).Col("deleted_at").IsNotNull(),
+		sql.T(table).Col("deleted_at").Lt(cutoff),
+	)
+}
+
+func count(ctx context.Context, model sql.Table, cutoff time.Time) (int64, error) {
+	query, args, err := pg.SQLBuilder().
+		From(model.TableName()).
+		Select(sql.CountALL).
+		Where(expired(model, cutoff)).
+		ToSQL()
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	return count, pg.Get(ctx, pg.EmptyTx(), &count, query, args...)
+}
+
+// remove: Hard-delete expired rows in batches, each batch in its own transaction
+func remove(ctx context.Context, model sql.Table, cutoff time.Time, size int) (int64, error) {
+	var total int64
+	for {
+		deleted, err := removeBatch(ctx, model, cutoff, size)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if deleted < int64(size) {
+			return total, nil
+		}
+	}
+}
+
+func removeBatch(ctx context.Context, model sql.Table, cutoff time.Time, size int) (int64, error) {
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	query, args, err := pg.SQLBuilder().
+		From(model.TableName()).
+		Select("id").
+		Where(expired(model, cutoff)).
+		Limit(uint(size)).
+		ForUpdate(exp.SkipLocked).
+		ToSQL()
+	if err != nil {
+		return 0, err
+	}
+	var ids []pgtype.UUID
+	if err := pg.Select(ctx, tx, &ids, query, args...); err != nil {
+		return 0, err
+	} else if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Revisions and suggestions reference their entity without a foreign key,
+	// a suggestion left behind could never be approved
+	if _, ok := model.(sql.Revisionable); ok {
+		entity := sql.And(
+			sql.I("entity_table").Eq(model.TableName()),
+			sql.I("entity_id").In(ids),
+		)
+		if _, err := sql.HardDelete(ctx, tx, sql.Revision{}, entity); err != nil {
+			return 0, err
+		}
+		if _, err := sql.HardDelete(ctx, tx, suggestion.Suggestion{}, entity); err != nil {
+			return 0, err
+		}
+	}
+
+	deleted, err := sql.HardDelete(ctx, tx, model, sql.I("id").In(ids))
+	if err != nil {
+		return 0, err
+	}
+	return deleted, tx.Commit(ctx)
+}
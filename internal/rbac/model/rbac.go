@@ -0,0 +1,119 @@
+package model
+
+import (
+	"context"
+
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	pgtype "github.com/jackc/pgtype"
+)
+
+// Roles seeded by the migrations
+const (
+	RoleEditor    = "editor"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Permissions seeded by the migrations
+const (
+	PermMovieWrite         = "movie:write"
+	PermPersonWrite        = "person:write"
+	PermRevisionRevert     = "revision:revert"
+	PermSuggestionModerate = "suggestion:moderate"
+	PermTrashRestore       = "trash:restore"
+	PermRoleAssign         = "role:assign"
+)
+
+/*============================================================================*/
+/*=====*                             Models                             *=====*/
+/*============================================================================*/
+
+type Role struct {
+	ID          pgtype.UUID `json:"id" db:"id"`
+	Name        string      `json:"name" db:"name"`
+	Description string      `json:"description" db:"description"`
+	// Grants of the role are withheld from users without two-factor
+	RequiresTwoFactor bool `json:"requires_two_factor" db:"requires_two_factor"`
+}
+
+func (Role) TableName() string { return "roles" }
+
+type Permission struct {
+	ID          pgtype.UUID `json:"id" db:"id"`
+	Name        string      `json:"name" db:"name"`
+	Description string      `json:"description" db:"description"`
+}
+
+func (Permission) TableName() string { return "permissions" }
+
+type RolePermission struct {
+	RoleID       pgtype.UUID `json:"role_id" db:"role_id"`
+	PermissionID pgtype.UUID `json:"permission_id" db:"permission_id"`
+}
+
+func (RolePermission) TableName() string { return "role_permissions" }
+
+type UserRole struct {
+	UserID pgtype.UUID `json:"user_id" db:"user_id"`
+	RoleID pgtype.UUID `json:"role_id" db:"role_id"`
+	sql.Created
+}
+
+func (UserRole) TableName() string { return "user_roles" }
+
+var userRoleConstraints = sql.NewConstraintRegistry().
+	Register("user_roles_pkey", "role_id", "unique", "Role is already granted").
+	Register("user_roles_user_id_fkey", "user_id", "", "").
+	Register("user_roles_role_id_fkey", "role_id", "", "")
+
+func (UserRole) Constraints() *sql.ConstraintRegistry { return userRoleConstraints }
+
+// Policy: Body of a role policy update
+type Policy struct {
+	RequiresTwoFactor *bool `json:"requires_two_factor" validate:"required"`
+}
+
+/*============================================================================*/
+/*=====*                              API                               *=====*/
+/*============================================================================*/
+
+// Grants: Roles of a user and the permissions they give
+//
+// Roles requiring two-factor authentication only count once the user enabled
+// it.
+func Grants(ctx context.Context, tx pg.Tx, userID pgtype.UUID) ([]string, []string, error) {
+	var grants []struct {
+		Role       string      `db:"role"`
+		Permission pgtype.Text `db:"permission"`
+	}
+	err := sql.Read[UserRole]().
+		Select(sql.I("r.name").As("role"), sql.I("p.name").As("permission")).
+		Join(sql.TAs(Role{}, "r"), sql.On(sql.I("r.id").Eq(sql.I("user_roles.role_id")))).
+		Join(sql.T("users").As("u"), sql.On(sql.I("u.id").Eq(sql.I("user_roles.user_id")))).
+		LeftJoin(sql.TAs(RolePermission{}, "rp"), sql.On(sql.I("rp.role_id").Eq(sql.I("r.id")))).
+		LeftJoin(sql.TAs(Permission{}, "p"), sql.On(sql.I("p.id").Eq(sql.I("rp.permission_id")))).
+		Where(
+			sql.I("user_roles.user_id").Eq(userID),
+			sql.Or(sql.I("r.requires_two_factor").IsFalse(), sql.I("u.totp_enabled_at").IsNotNull()),
+		).
+		Sel(ctx, tx, &grants)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roles, permissions := []string{}, []string{}
+	seen := map[string]bool{}
+	for _, grant := range grants {
+		if !seen["role:"+grant.Role] {
+			seen["role:"+grant.Role] = true
+			roles = append(roles, grant.Role)
+		}
+		if grant.Permission.Status == pgtype.Present && !seen["perm:"+grant.Permission.String] {
+			seen["perm:"+grant.Permission.String] = true
+			permissions = append(permissions, grant.Permission.String)
+		}
+	}
+	return roles, permissions, nil
+}
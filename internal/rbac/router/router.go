@@ -0,0 +1,167 @@
+package router
+
+import (
+	"context"
+	"net/http"
+
+	middleware "movies/internal/auth/middleware"
+	auth "movies/internal/auth/model"
+	model "movies/internal/rbac/model"
+	user "movies/internal/user/model"
+	cerrors "movies/utils/cerrors"
+	form "movies/utils/form"
+	pg "movies/utils/pg"
+	render "movies/utils/render"
+	sql "movies/utils/sql"
+
+	mux "github.com/gorilla/mux"
+	pgtype "github.com/jackc/pgtype"
+)
+
+type RBACRouter struct {
+	router *mux.Router
+}
+
+func NewRBACRouter(r *mux.Router) *RBACRouter {
+	return &RBACRouter{router: r}
+}
+
+func (obj *RBACRouter) Handle() {
+	s := obj.router.PathPrefix("/admin").Subrouter()
+	s.Use(middleware.Require(model.PermRoleAssign), middleware.RequireScope(auth.ScopeAdmin))
+	s.HandleFunc("/roles", render.Handler(obj.list)).Methods(http.MethodGet)
+	s.HandleFunc("/roles/{role}", render.Handler(obj.policy)).Methods(http.MethodPatch)
+	s.HandleFunc("/users/{id}/roles/{role}", render.Handler(obj.grant)).Methods(http.MethodPut)
+	s.HandleFunc("/users/{id}/roles/{role}", render.Handler(obj.revoke)).Methods(http.MethodDelete)
+}
+
+// list: List the roles with their permissions
+func (obj *RBACRouter) list(w http.ResponseWriter, r *http.Request) error {
+	var roles []struct {
+		model.Role
+		Permissions []string `json:"permissions" db:"permissions"`
+	}
+	err := sql.Read[model.Role]().
+		Select(
+			sql.I("roles.*"),
+			sql.L("COALESCE(array_agg(p.name ORDER BY p.name) FILTER (WHERE p.name IS NOT NULL), '{}')").As("permissions"),
+		).
+		LeftJoin(sql.TAs(model.RolePermission{}, "rp"), sql.On(sql.I("rp.role_id").Eq(sql.I("roles.id")))).
+		LeftJoin(sql.TAs(model.Permission{}, "p"), sql.On(sql.I("p.id").Eq(sql.I("rp.permission_id")))).
+		GroupBy(sql.I("roles.id")).
+		Order(sql.I("roles.name").Asc()).
+		Sel(r.Context(), pg.EmptyTx(), &roles)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, roles)
+	return nil
+}
+
+// policy: Require two-factor authentication for the grants of a role
+func (obj *RBACRouter) policy(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var body model.Policy
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+	role, err := findRole(ctx, pg.EmptyTx(), mux.Vars(r)["role"])
+	if err != nil {
+		return err
+	}
+
+	if err := sql.Update(ctx, pg.EmptyTx(), role, false, sql.Record{
+		"requires_two_factor": *body.RequiresTwoFactor,
+	}, sql.I("id").Eq(role.ID)); err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, role)
+	return nil
+}
+
+// grant: Give a role to a user
+func (obj *RBACRouter) grant(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	userID, err := parseID(r)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := sql.Read[user.User]().Where(sql.I("id").Eq(userID)).FindOne(ctx, tx); err != nil {
+		return err
+	}
+	role, err := findRole(ctx, tx, mux.Vars(r)["role"])
+	if err != nil {
+		return err
+	}
+
+	count, err := sql.Read[model.UserRole]().
+		Where(sql.I("user_id").Eq(userID), sql.I("role_id").Eq(role.ID)).
+		Count(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := sql.Create(ctx, tx, &model.UserRole{}, sql.Record{
+			"user_id": userID,
+			"role_id": role.ID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	render.NoContent(w)
+	return nil
+}
+
+// revoke: Take a role back from a user
+func (obj *RBACRouter) revoke(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	userID, err := parseID(r)
+	if err != nil {
+		return err
+	}
+	role, err := findRole(ctx, pg.EmptyTx(), mux.Vars(r)["role"])
+	if err != nil {
+		return err
+	}
+
+	_, err = sql.HardDelete(ctx, pg.EmptyTx(), model.UserRole{}, sql.And(
+		sql.I("user_id").Eq(userID),
+		sql.I("role_id").Eq(role.ID),
+	))
+	if err != nil {
+		return err
+	}
+	render.NoContent(w)
+	return nil
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+func findRole(ctx context.Context, tx pg.Tx, name string) (*model.Role, error) {
+	return sql.Read[model.Role]().Where(sql.I("name").Eq(name)).FindOne(ctx, tx)
+}
+
+func parseID(r *http.Request) (pgtype.UUID, error) {
+	value := mux.Vars(r)["id"]
+	id, err := pg.ParseUUID(value)
+	if err != nil {
+		return id, cerrors.NewValidation("uuid", "id", "`"+value+"` is not a valid UUID", value)
+	}
+	return id, nil
+}
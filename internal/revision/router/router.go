@@ -0,0 +1,141 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+
+	middleware "movies/internal/auth/middleware"
+	auth "movies/internal/auth/model"
+	catalog "movies/internal/catalog/model"
+	rbac "movies/internal/rbac/model"
+	cerrors "movies/utils/cerrors"
+	pg "movies/utils/pg"
+	render "movies/utils/render"
+	sql "movies/utils/sql"
+
+	mux "github.com/gorilla/mux"
+	pgtype "github.com/jackc/pgtype"
+)
+
+/*============================================================================*/
+/*=====*                             Router                             *=====*/
+/*============================================================================*/
+
+type RevisionRouter struct {
+	router *mux.Router
+}
+
+func NewRevisionRouter(r *mux.Router) *RevisionRouter {
+	return &RevisionRouter{router: r}
+}
+
+func (obj *RevisionRouter) Handle() {
+	s := obj.router.PathPrefix("/revisions/{entity}/{id}").Subrouter()
+	s.HandleFunc("", render.Handler(obj.list)).Methods(http.MethodGet)
+	s.HandleFunc("/diff", render.Handler(obj.diff)).Methods(http.MethodGet)
+	s.HandleFunc("/{version}/revert", render.Handler(obj.revert)).Methods(http.MethodPost)
+}
+
+// list: List the revisions of an entity
+func (obj *RevisionRouter) list(w http.ResponseWriter, r *http.Request) error {
+	name, _, id, err := parseEntity(r)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := sql.Revisions(r.Context(), pg.EmptyTx(), name, id)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, revisions)
+	return nil
+}
+
+// diff: Changes of an entity between two versions
+func (obj *RevisionRouter) diff(w http.ResponseWriter, r *http.Request) error {
+	name, _, id, err := parseEntity(r)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	from, err := parseVersion("from", query.Get("from"))
+	if err != nil {
+		return err
+	}
+	to, err := parseVersion("to", query.Get("to"))
+	if err != nil {
+		return err
+	}
+
+	diff, err := sql.DiffRevisions(r.Context(), pg.EmptyTx(), name, id, from, to)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, diff)
+	return nil
+}
+
+// revert: Restore an entity to a version
+func (obj *RevisionRouter) revert(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if !middleware.Can(w, r, rbac.PermRevisionRevert) || !middleware.HasScope(w, r, auth.ScopeCatalogWrite) {
+		return nil
+	}
+	_, entity, id, err := parseEntity(r)
+	if err != nil {
+		return err
+	}
+	version, err := parseVersion("version", mux.Vars(r)["version"])
+	if err != nil {
+		return err
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	item, err := entity.Find(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := sql.RevertByPK(ctx, tx, item, version, entity.Editable, sql.Record{}); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, item)
+	return nil
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+func parseEntity(r *http.Request) (string, catalog.Entity, pgtype.UUID, error) {
+	vars := mux.Vars(r)
+
+	name := vars["entity"]
+	entity, ok := catalog.Entities[name]
+	if !ok {
+		return name, entity, pgtype.UUID{}, cerrors.NewValidation("oneof", "entity", "`"+name+"` has no revisions", name)
+	}
+
+	id, err := pg.ParseUUID(vars["id"])
+	if err != nil {
+		return name, entity, id, cerrors.NewValidation("uuid", "id", "`"+vars["id"]+"` is not a valid UUID", vars["id"])
+	}
+	return name, entity, id, nil
+}
+
+func parseVersion(field, value string) (int, error) {
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 0 {
+		return 0, cerrors.NewValidation("min", field, "`"+value+"` is not a valid version", value)
+	}
+	return version, nil
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"encoding/json"
+
+	sql "movies/utils/sql"
+
+	pgtype "github.com/jackc/pgtype"
+	lo "github.com/samber/lo"
+)
+
+/*============================================================================*/
+/*=====*                             Status                             *=====*/
+/*============================================================================*/
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+func (s Status) IsValid() bool {
+	return lo.Contains([]Status{StatusPending, StatusApproved, StatusRejected}, s)
+}
+
+/*============================================================================*/
+/*=====*                           Suggestion                           *=====*/
+/*============================================================================*/
+
+// Reputation gained by the author of an approved suggestion
+const ApprovedReputation = 10
+
+type Suggestion struct {
+	sql.Extended
+	EntityTable   string             `json:"entity_table" db:"entity_table"`
+	EntityID      pgtype.UUID        `json:"entity_id" db:"entity_id"`
+	Changes       pgtype.JSONB       `json:"changes" db:"changes"`
+	Comment       string             `json:"comment" db:"comment"`
+	Status        Status             `json:"status" db:"status" validate:"enum"`
+	ReviewedAt    pgtype.Timestamptz `json:"reviewed_at" db:"reviewed_at"`
+	ReviewedBy    pgtype.UUID        `json:"reviewed_by" db:"reviewed_by"`
+	ReviewComment string             `json:"review_comment" db:"review_comment"`
+}
+
+func (Suggestion) TableName() string { return "suggestions" }
+
+var suggestionListing = sql.NewListSpec().
+	Filter("created_by", sql.OpEq, sql.OpIn).
+	Filter("created_at", sql.OpGte, sql.OpLte).
+	Sort("created_at").
+	Field("id", "entity_table", "entity_id", "changes", "comment", "status", "created_at", "created_by")
+
+func (Suggestion) Listing() *sql.ListSpec { return suggestionListing }
+
+func (obj Suggestion) GetChanges() (map[string]any, error) {
+	changes := make(map[string]any)
+	return changes, json.Unmarshal(obj.Changes.Bytes, &changes)
+}
+
+/*============================================================================*/
+/*=====*                              Form                              *=====*/
+/*============================================================================*/
+
+// Create: Body of a new suggestion
+type Create struct {
+	EntityTable string          `json:"entity_table" validate:"required"`
+	EntityID    pgtype.UUID     `json:"entity_id" validate:"required"`
+	Changes     json.RawMessage `json:"changes" validate:"required"`
+	Comment     string          `json:"comment" validate:"max=1000"`
+}
+
+// Queue: Query of the moderation queue, status can be repeated
+type Queue struct {
+	Status      []Status    `query:"status" validate:"min=1,dive,enum"`
+	EntityTable string      `query:"entity_table"`
+	EntityID    pgtype.UUID `query:"entity_id"`
+	Cursor      string      `query:"cursor"`
+	Size        uint        `query:"size" validate:"max=100"`
+}
+
+// Review: Body of a moderation decision
+type Review struct {
+	Comment string `json:"comment" validate:"max=1000"`
+}
@@ -0,0 +1,309 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	middleware "movies/internal/auth/middleware"
+	auth "movies/internal/auth/model"
+	catalog "movies/internal/catalog/model"
+	rbac "movies/internal/rbac/model"
+	model "movies/internal/suggestion/model"
+	user "movies/internal/user/model"
+	cerrors "movies/utils/cerrors"
+	form "movies/utils/form"
+	pg "movies/utils/pg"
+	principal "movies/utils/principal"
+	render "movies/utils/render"
+	sql "movies/utils/sql"
+
+	exp "github.com/doug-martin/goqu/v9/exp"
+	mux "github.com/gorilla/mux"
+	pgtype "github.com/jackc/pgtype"
+	lo "github.com/samber/lo"
+)
+
+type SuggestionRouter struct {
+	router *mux.Router
+}
+
+func NewSuggestionRouter(r *mux.Router) *SuggestionRouter {
+	return &SuggestionRouter{router: r}
+}
+
+func (obj *SuggestionRouter) Handle() {
+	s := obj.router.PathPrefix("/suggestions").Subrouter()
+	s.HandleFunc("", render.Handler(obj.create)).Methods(http.MethodPost)
+	s.HandleFunc("", render.Handler(obj.queue)).Methods(http.MethodGet)
+	s.HandleFunc("/{id}", render.Handler(obj.get)).Methods(http.MethodGet)
+	s.HandleFunc("/{id}/approve", render.Handler(obj.review(model.StatusApproved))).Methods(http.MethodPost)
+	s.HandleFunc("/{id}/reject", render.Handler(obj.review(model.StatusRejected))).Methods(http.MethodPost)
+}
+
+// create: Propose changes to a catalog entity
+func (obj *SuggestionRouter) create(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if !middleware.HasScope(w, r, auth.ScopeSuggestionsWrite) {
+		return nil
+	}
+
+	var body model.Create
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+	entity, ok := catalog.Entities[body.EntityTable]
+	if !ok {
+		return cerrors.NewValidation("oneof", "entity_table", "`"+body.EntityTable+"` cannot be edited", body.EntityTable)
+	}
+	if err := checkChanges(entity, body.Changes); err != nil {
+		return err
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Validate the changes applied to the current record
+	item, err := entity.Find(ctx, tx, body.EntityID)
+	if err != nil {
+		return err
+	}
+	if err := form.ValidateJSON(r.Context(), bytes.NewReader(body.Changes), item); err != nil {
+		return err
+	}
+
+	suggestion := &model.Suggestion{}
+	if err := sql.Create(ctx, tx, suggestion, sql.Record{
+		"entity_table": body.EntityTable,
+		"entity_id":    body.EntityID,
+		"changes":      pg.NewJSONBFromBytes(body.Changes),
+		"comment":      body.Comment,
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusCreated, suggestion)
+	return nil
+}
+
+// queue: Page through suggestions awaiting moderation, oldest first unless
+// sorted otherwise
+func (obj *SuggestionRouter) queue(w http.ResponseWriter, r *http.Request) error {
+	if !middleware.Can(w, r, rbac.PermSuggestionModerate) || !middleware.HasScope(w, r, auth.ScopeCatalogRead) {
+		return nil
+	}
+
+	query := model.Queue{Status: []model.Status{model.StatusPending}}
+	if err := form.ValidateQuery(r, &query); err != nil {
+		return err
+	}
+	list, err := sql.ParseList[model.Suggestion](r.URL.Query())
+	if err != nil {
+		return err
+	}
+	if len(list.Sort) == 0 {
+		list.Sort = []sql.Sort{sql.Asc("created_at")}
+	}
+
+	filters := []exp.Expression{sql.I("status").In(query.Status)}
+	if query.EntityTable != "" {
+		filters = append(filters, sql.I("entity_table").Eq(query.EntityTable))
+	}
+	if query.EntityID.Status == pgtype.Present {
+		filters = append(filters, sql.I("entity_id").Eq(query.EntityID))
+	}
+
+	suggestions, err := sql.Read[model.Suggestion]().
+		Where(filters...).
+		List(list).
+		Paginate(r.Context(), pg.EmptyTx(), query.Cursor, query.Size, list.Sort...)
+	if err != nil {
+		return err
+	}
+	page, err := suggestions.Project(list.Fields)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, page)
+	return nil
+}
+
+// get: Get a suggestion with its field-level diff against the current record
+func (obj *SuggestionRouter) get(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if !middleware.HasScope(w, r, auth.ScopeCatalogRead) {
+		return nil
+	}
+	id, err := parseID(r)
+	if err != nil {
+		return err
+	}
+
+	suggestion, err := sql.Read[model.Suggestion]().Where(sql.I("id").Eq(id)).FindOne(ctx, pg.EmptyTx())
+	if err != nil {
+		return err
+	}
+	diff, err := diffSuggestion(ctx, pg.EmptyTx(), suggestion)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, map[string]any{
+		"suggestion": suggestion,
+		"diff":       diff,
+	})
+	return nil
+}
+
+// review: Approve or reject a pending suggestion
+func (obj *SuggestionRouter) review(status model.Status) render.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx := r.Context()
+
+		if !middleware.Can(w, r, rbac.PermSuggestionModerate) || !middleware.HasScope(w, r, auth.ScopeCatalogWrite) {
+			return nil
+		}
+		p, _ := principal.FromContext(ctx)
+
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+		var body model.Review
+		if r.ContentLength != 0 {
+			if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+				return err
+			}
+		}
+
+		tx, err := pg.NewTx(ctx)
+		defer tx.RollbackDefer(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Claim the suggestion, a concurrent review will not find it pending
+		suggestion := &model.Suggestion{}
+		if err := sql.Update(ctx, tx, suggestion, true, sql.Record{
+			"status":         status,
+			"reviewed_at":    sql.NOW,
+			"reviewed_by":    p.UserID,
+			"review_comment": body.Comment,
+		}, sql.And(
+			sql.I("id").Eq(id),
+			sql.I("status").Eq(model.StatusPending),
+		)); err != nil {
+			return err
+		}
+
+		if status == model.StatusApproved {
+			if err := applySuggestion(ctx, tx, suggestion); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		render.JSON(w, r, http.StatusOK, suggestion)
+		return nil
+	}
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+// checkChanges: Ensure only editable columns are changed
+func checkChanges(entity catalog.Entity, raw json.RawMessage) error {
+	changes := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(raw, &changes); err != nil {
+		return cerrors.NewValidation("json", "changes", "`changes` must be a JSON object", string(raw))
+	} else if len(changes) == 0 {
+		return cerrors.NewValidation("required", "changes", "`changes` is a required field", string(raw))
+	}
+
+	var er *cerrors.Error
+	for column := range changes {
+		if !lo.Contains(entity.Editable, column) {
+			er = er.Append(cerrors.NewValidation("oneof", "changes."+column, "`"+column+"` cannot be edited", column))
+		}
+	}
+	if er != nil {
+		return er
+	}
+	return nil
+}
+
+// diffSuggestion: Field-level diff between the current record and a suggestion
+func diffSuggestion(ctx context.Context, tx pg.Tx, suggestion *model.Suggestion) (sql.Diff, error) {
+	entity, ok := catalog.Entities[suggestion.EntityTable]
+	if !ok {
+		return nil, cerrors.NewString("`%s` cannot be edited", suggestion.EntityTable)
+	}
+	item, err := entity.Find(ctx, tx, suggestion.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := sql.SnapshotByPK(ctx, tx, item)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := suggestion.GetChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	diff := sql.Diff{}
+	for column, value := range changes {
+		diff[column] = sql.Change{Old: current[column], New: value}
+	}
+	return diff, nil
+}
+
+// applySuggestion: Apply an approved suggestion and reward its author
+//
+// Changes are validated again against the current model, and the revision is
+// attributed to the moderator of the context.
+func applySuggestion(ctx context.Context, tx pg.Tx, suggestion *model.Suggestion) error {
+	entity, ok := catalog.Entities[suggestion.EntityTable]
+	if !ok {
+		return cerrors.NewString("`%s` cannot be edited", suggestion.EntityTable)
+	}
+	item, err := entity.Find(ctx, tx, suggestion.EntityID)
+	if err != nil {
+		return err
+	}
+	changes := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(suggestion.Changes.Bytes, &changes); err != nil {
+		return err
+	}
+	record, err := sql.PatchRecord(ctx, item, entity.Editable, changes)
+	if err != nil {
+		return err
+	}
+	if err := sql.UpdateByPK(ctx, tx, item, true, record); err != nil {
+		return err
+	}
+
+	author := &user.User{}
+	return sql.Update(ctx, tx, author, false, sql.Record{
+		"reputation": sql.L("? + ?", sql.I("reputation"), model.ApprovedReputation),
+	}, sql.I("id").Eq(suggestion.CreatedBy))
+}
+
+func parseID(r *http.Request) (pgtype.UUID, error) {
+	value := mux.Vars(r)["id"]
+	id, err := pg.ParseUUID(value)
+	if err != nil {
+		return id, cerrors.NewValidation("uuid", "id", "`"+value+"` is not a valid UUID", value)
+	}
+	return id, nil
+}
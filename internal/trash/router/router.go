@@ -0,0 +1,110 @@
+package router
+
+import (
+	"net/http"
+
+	middleware "movies/internal/auth/middleware"
+	auth "movies/internal/auth/model"
+	catalog "movies/internal/catalog/model"
+	rbac "movies/internal/rbac/model"
+	cerrors "movies/utils/cerrors"
+	pg "movies/utils/pg"
+	render "movies/utils/render"
+	sql "movies/utils/sql"
+
+	mux "github.com/gorilla/mux"
+	pgtype "github.com/jackc/pgtype"
+)
+
+type TrashRouter struct {
+	router *mux.Router
+}
+
+func NewTrashRouter(r *mux.Router) *TrashRouter {
+	return &TrashRouter{router: r}
+}
+
+func (obj *TrashRouter) Handle() {
+	s := obj.router.PathPrefix("/admin/trash/{entity}").Subrouter()
+	s.Use(middleware.Require(rbac.PermTrashRestore), middleware.RequireScope(auth.ScopeAdmin))
+	s.HandleFunc("", render.Handler(obj.list)).Methods(http.MethodGet)
+	s.HandleFunc("/{id}/restore", render.Handler(obj.restore)).Methods(http.MethodPost)
+}
+
+// list: List deleted and archived rows of an entity
+func (obj *TrashRouter) list(w http.ResponseWriter, r *http.Request) error {
+	entity, err := parseEntity(r)
+	if err != nil {
+		return err
+	}
+
+	items, err := entity.ListTrashed(r.Context(), pg.EmptyTx())
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, items)
+	return nil
+}
+
+// restore: Undelete and unarchive a row
+func (obj *TrashRouter) restore(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	entity, err := parseEntity(r)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(r)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	item, err := entity.FindTrashed(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if item.GetDeleted().DeletedAt.Status == pgtype.Present {
+		if err := sql.RestoreByPK(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+	if item.GetArchived().ArchivedAt.Status == pgtype.Present {
+		if err := sql.UnarchiveByPK(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, item)
+	return nil
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+func parseEntity(r *http.Request) (catalog.Entity, error) {
+	name := mux.Vars(r)["entity"]
+	entity, ok := catalog.Entities[name]
+	if !ok {
+		return entity, cerrors.NewValidation("oneof", "entity", "`"+name+"` has no trash", name)
+	}
+	return entity, nil
+}
+
+func parseID(r *http.Request) (pgtype.UUID, error) {
+	value := mux.Vars(r)["id"]
+	id, err := pg.ParseUUID(value)
+	if err != nil {
+		return id, cerrors.NewValidation("uuid", "id", "`"+value+"` is not a valid UUID", value)
+	}
+	return id, nil
+}
@@ -0,0 +1,19 @@
+package gdpr
+
+import (
+	"context"
+
+	user "movies/internal/user/model"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+)
+
+// Delete: Soft-delete an account and revoke its credentials at once
+//
+// Personal data stays until the grace period is over, then `Run` erases it.
+func Delete(ctx context.Context, tx pg.Tx, u *user.User) error {
+	if err := sql.SoftDeleteByPK(ctx, tx, u); err != nil {
+		return err
+	}
+	return run(ctx, tx, u.ID, credentials)
+}
@@ -0,0 +1,159 @@
+package gdpr
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	auth "movies/internal/auth/model"
+	oauth "movies/internal/oauth/model"
+	rbac "movies/internal/rbac/model"
+	suggestion "movies/internal/suggestion/model"
+	user "movies/internal/user/model"
+	config "movies/utils/config"
+	logger "movies/utils/logger"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	errors "emperror.dev/errors"
+	pgtype "github.com/jackc/pgtype"
+)
+
+/*============================================================================*/
+/*=====*                            Erasers                             *=====*/
+/*============================================================================*/
+
+type eraser struct {
+	name  string
+	erase func(ctx context.Context, tx pg.Tx, userID pgtype.UUID) error
+}
+
+// credentials: Removed as soon as an account is deleted
+var credentials = []eraser{
+	{"sessions", deleteRows(auth.Session{}, "user_id")},
+	{"refresh_tokens", deleteRows(auth.RefreshToken{}, "user_id")},
+	{"personal_access_tokens", deleteRows(auth.PersonalAccessToken{}, "user_id")},
+	{"oauth_tokens", deleteRows(oauth.Token{}, "user_id")},
+	{"oauth_codes", deleteRows(oauth.Code{}, "user_id")},
+}
+
+// erasers: Personal data removed or anonymized after the grace period, in order
+//
+// Contributions to the catalog are kept, attributed to the anonymized user.
+var erasers = []eraser{
+	{"recovery_codes", deleteRows(auth.RecoveryCode{}, "user_id")},
+	{"email_tokens", deleteRows(auth.EmailToken{}, "user_id")},
+	{"oauth_consents", deleteRows(oauth.Consent{}, "user_id")},
+	{"oauth_clients", deleteRows(oauth.Client{}, "owner_id")},
+	{"user_roles", deleteRows(rbac.UserRole{}, "user_id")},
+	{"suggestions", anonymizeSuggestions},
+	{"users", anonymizeUser},
+}
+
+func deleteRows(model sql.Table, column string) func(context.Context, pg.Tx, pgtype.UUID) error {
+	return func(ctx context.Context, tx pg.Tx, userID pgtype.UUID) error {
+		_, err := sql.HardDelete(ctx, tx, model, sql.I(column).Eq(userID))
+		return err
+	}
+}
+
+// anonymizeSuggestions: Drop the free text of suggestions, keep the changes
+func anonymizeSuggestions(ctx context.Context, tx pg.Tx, userID pgtype.UUID) error {
+	query, args, err := pg.SQLBuilder().
+		Update(suggestion.Suggestion{}.TableName()).
+		Set(sql.Record{"comment": ""}).
+		Where(sql.I("created_by").Eq(userID)).
+		ToSQL()
+	if err != nil {
+		return err
+	}
+	_, err = pg.Client(tx).Exec(ctx, query, args...)
+	return err
+}
+
+// anonymizeUser: Replace the identity of the user, the row stays referenced
+func anonymizeUser(ctx context.Context, tx pg.Tx, userID pgtype.UUID) error {
+	return sql.Update(ctx, tx, &user.User{}, false, sql.Record{
+		"username":          "deleted_" + strings.ReplaceAll(pg.FormatUUID(userID), "-", ""),
+		"email":             nil,
+		"email_verified_at": nil,
+		"password_hash":     nil,
+		"totp_secret":       nil,
+		"totp_enabled_at":   nil,
+		"settings":          sql.L("'{}'::jsonb"),
+		"anonymized_at":     sql.NOW,
+	}, sql.I("id").Eq(userID))
+}
+
+/*============================================================================*/
+/*=====*                              Run                               *=====*/
+/*============================================================================*/
+
+// Erase: Remove the personal data of a user in one transaction
+func Erase(ctx context.Context, tx pg.Tx, userID pgtype.UUID) error {
+	tx, err := pg.EnsureTx(ctx, tx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := run(ctx, tx, userID, credentials); err != nil {
+		return err
+	}
+	if err := run(ctx, tx, userID, erasers); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func run(ctx context.Context, tx pg.Tx, userID pgtype.UUID, erasers []eraser) error {
+	for _, e := range erasers {
+		if err := e.erase(ctx, tx, userID); err != nil {
+			return errors.WrapIff(err, "erase `%s`", e.name)
+		}
+	}
+	return nil
+}
+
+// Run: Erase the accounts deleted for longer than the grace period
+func Run(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-config.Account().DeletionGrace())
+	users, err := sql.Read[user.User]().
+		OnlyDeleted().
+		Where(
+			sql.I("deleted_at").Lt(cutoff),
+			sql.I("anonymized_at").IsNull(),
+		).
+		FindAll(ctx, pg.EmptyTx())
+	if err != nil {
+		return 0, err
+	}
+
+	for i, u := range users {
+		if err := Erase(ctx, pg.EmptyTx(), u.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(users), nil
+}
+
+// Schedule: Run the erasure at every interval until the context is done
+func Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := Run(ctx)
+			if err != nil {
+				logger.Error(ctx, "Account erasure failed: %v", err)
+			}
+			if count > 0 {
+				logger.Info(ctx, "Erased %d deleted accounts", count)
+			}
+		}
+	}
+}
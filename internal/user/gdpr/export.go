@@ -0,0 +1,156 @@
+package gdpr
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+
+	auth "movies/internal/auth/model"
+	oauth "movies/internal/oauth/model"
+	suggestion "movies/internal/suggestion/model"
+	user "movies/internal/user/model"
+	pg "movies/utils/pg"
+	sql "movies/utils/sql"
+
+	errors "emperror.dev/errors"
+	pgtype "github.com/jackc/pgtype"
+)
+
+/*============================================================================*/
+/*=====*                            Sections                            *=====*/
+/*============================================================================*/
+
+// Section: Part of an export, written as `<name>.json` and `<name>.csv`
+type Section struct {
+	Name   string
+	Export func(ctx context.Context, tx pg.Tx, userID pgtype.UUID) (any, error)
+}
+
+// Sections: Personal data of a user
+//
+// Every table holding personal data needs a section, and an eraser.
+var Sections = []Section{
+	{Name: "profile", Export: profile},
+	{Name: "settings", Export: settings},
+	{Name: "suggestions", Export: rowsOf[suggestion.Suggestion]("created_by")},
+	{Name: "revisions", Export: rowsOf[sql.Revision]("updated_by")},
+	{Name: "sessions", Export: rowsOf[auth.Session]("user_id")},
+	{Name: "personal_access_tokens", Export: rowsOf[auth.PersonalAccessToken]("user_id")},
+	{Name: "oauth_clients", Export: rowsOf[oauth.Client]("owner_id")},
+	{Name: "oauth_consents", Export: rowsOf[oauth.Consent]("user_id")},
+}
+
+func profile(ctx context.Context, tx pg.Tx, userID pgtype.UUID) (any, error) {
+	return findUser(ctx, tx, userID)
+}
+
+func settings(ctx context.Context, tx pg.Tx, userID pgtype.UUID) (any, error) {
+	u, err := findUser(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return user.Settings.Values(u.Setting)
+}
+
+// rowsOf: Rows of a model whose column references the user
+func rowsOf[M sql.Table](column string) func(context.Context, pg.Tx, pgtype.UUID) (any, error) {
+	return func(ctx context.Context, tx pg.Tx, userID pgtype.UUID) (any, error) {
+		return sql.Read[M]().WithDeleted().Where(sql.I(column).Eq(userID)).FindAll(ctx, tx)
+	}
+}
+
+func findUser(ctx context.Context, tx pg.Tx, userID pgtype.UUID) (*user.User, error) {
+	return sql.Read[user.User]().WithDeleted().Where(sql.I("id").Eq(userID)).FindOne(ctx, tx)
+}
+
+/*============================================================================*/
+/*=====*                             Export                             *=====*/
+/*============================================================================*/
+
+// Export: Write the zip of every section of a user
+func Export(ctx context.Context, tx pg.Tx, userID pgtype.UUID, w io.Writer) error {
+	archive := zip.NewWriter(w)
+	for _, section := range Sections {
+		data, err := section.Export(ctx, tx, userID)
+		if err != nil {
+			return errors.WrapIff(err, "export `%s`", section.Name)
+		}
+		if err := writeSection(archive, section.Name, data); err != nil {
+			return err
+		}
+	}
+	return errors.WithStack(archive.Close())
+}
+
+func writeSection(archive *zip.Writer, name string, data any) error {
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	file, err := archive.Create(name + ".json")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := file.Write(raw); err != nil {
+		return errors.WithStack(err)
+	}
+
+	file, err = archive.Create(name + ".csv")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return writeCSV(file, raw)
+}
+
+// writeCSV: Flatten JSON rows into a CSV, nested values stay JSON encoded
+func writeCSV(w io.Writer, raw []byte) error {
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		var row map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return errors.WithStack(err)
+		}
+		rows = []map[string]json.RawMessage{row}
+	}
+
+	columns := []string{}
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for column := range row {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	out := csv.NewWriter(w)
+	if err := out.Write(columns); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = cell(row[column])
+		}
+		if err := out.Write(record); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	out.Flush()
+	return errors.WithStack(out.Error())
+}
+
+func cell(value json.RawMessage) string {
+	var str string
+	if len(value) == 0 || string(value) == "null" {
+		return ""
+	} else if err := json.Unmarshal(value, &str); err == nil {
+		return str
+	}
+	return string(value)
+}
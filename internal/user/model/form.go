@@ -0,0 +1,8 @@
+package model
+
+// Delete: Body confirming the deletion of an account
+type Delete struct {
+	Password string `json:"password" validate:"required,max=256"`
+	// TOTP or recovery code, when two-factor is enabled
+	Code string `json:"code" validate:"omitempty,max=32"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	sql "movies/utils/sql"
+)
+
+// Settings: User preferences, stored in `users.settings`
+var Settings = sql.NewSettingRegistry()
+
+var (
+	SettingLanguage           = sql.RegisterSetting(Settings, "language", "en", "oneof=en fr")
+	SettingIncludeAdult       = sql.RegisterSetting(Settings, "include_adult", false, "")
+	SettingRatingScale        = sql.RegisterSetting(Settings, "rating_scale", 10, "oneof=5 10 100")
+	SettingEmailNotifications = sql.RegisterSetting(Settings, "email_notifications", true, "")
+)
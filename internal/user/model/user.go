@@ -0,0 +1,36 @@
+package model
+
+import (
+	sql "movies/utils/sql"
+
+	pgtype "github.com/jackc/pgtype"
+)
+
+type User struct {
+	sql.Extended
+	sql.Setting
+	Username     string      `json:"username" db:"username" validate:"required,alphanumdot,max=64"`
+	Email        pgtype.Text `json:"email" db:"email"`
+	PasswordHash pgtype.Text `json:"-" db:"password_hash"`
+	Reputation   int         `json:"reputation" db:"reputation"`
+
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at" db:"email_verified_at"`
+	AnonymizedAt    pgtype.Timestamptz `json:"anonymized_at" db:"anonymized_at"`
+
+	TOTPSecret      pgtype.Text        `json:"-" db:"totp_secret"`
+	TOTPEnabledAt   pgtype.Timestamptz `json:"totp_enabled_at" db:"totp_enabled_at"`
+	TOTPLastCounter int64              `json:"-" db:"totp_last_counter"`
+}
+
+func (User) TableName() string { return "users" }
+
+var userConstraints = sql.NewConstraintRegistry().
+	Register("users_username_key", "username", "unique", "`username` is already taken").
+	Register("users_email_key", "email", "unique", "`email` is already registered")
+
+func (User) Constraints() *sql.ConstraintRegistry { return userConstraints }
+
+// HasTwoFactor: Whether the user confirmed a TOTP enrolment
+func (obj User) HasTwoFactor() bool {
+	return obj.TOTPEnabledAt.Status == pgtype.Present
+}
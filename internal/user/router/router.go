@@ -0,0 +1,197 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+
+	middleware "movies/internal/auth/middleware"
+	auth "movies/internal/auth/model"
+	password "movies/internal/auth/password"
+	session "movies/internal/auth/session"
+	twofactor "movies/internal/auth/twofactor"
+	gdpr "movies/internal/user/gdpr"
+	model "movies/internal/user/model"
+	cerrors "movies/utils/cerrors"
+	config "movies/utils/config"
+	form "movies/utils/form"
+	pg "movies/utils/pg"
+	principal "movies/utils/principal"
+	render "movies/utils/render"
+	sql "movies/utils/sql"
+
+	mux "github.com/gorilla/mux"
+	pgtype "github.com/jackc/pgtype"
+)
+
+type UserRouter struct {
+	router *mux.Router
+}
+
+func NewUserRouter(r *mux.Router) *UserRouter {
+	return &UserRouter{router: r}
+}
+
+func (obj *UserRouter) Handle() {
+	s := obj.router.PathPrefix("/users/me").Subrouter()
+	s.HandleFunc("/settings", render.Handler(obj.getSettings)).Methods(http.MethodGet)
+	s.HandleFunc("/settings", render.Handler(obj.patchSettings)).Methods(http.MethodPatch)
+	s.HandleFunc("/export", render.Handler(obj.export)).Methods(http.MethodGet)
+	s.HandleFunc("/delete", render.Handler(obj.delete)).Methods(http.MethodPost)
+}
+
+// getSettings: Settings of the current user, with defaults
+func (obj *UserRouter) getSettings(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if !middleware.HasScope(w, r, auth.ScopeProfileRead) {
+		return nil
+	}
+	p, _ := principal.FromContext(ctx)
+
+	user, err := sql.Read[model.User]().Where(sql.I("id").Eq(p.UserID)).FindOne(ctx, pg.EmptyTx())
+	if err != nil {
+		return err
+	}
+	settings, err := model.Settings.Values(user.Setting)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, settings)
+	return nil
+}
+
+// patchSettings: Update some settings of the current user
+func (obj *UserRouter) patchSettings(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if !middleware.HasScope(w, r, auth.ScopeProfileWrite) {
+		return nil
+	}
+	p, _ := principal.FromContext(ctx)
+
+	var body map[string]json.RawMessage
+	if err := form.DecodeJSON(r.Body, &body); err != nil {
+		return err
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	user, err := sql.Read[model.User]().Where(sql.I("id").Eq(p.UserID)).ForUpdate().FindOne(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if err := model.Settings.Patch(&user.Setting, body); err != nil {
+		return err
+	}
+	if err := sql.UpdateByPK(ctx, tx, user, true, sql.Record{"settings": user.Settings}); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	settings, err := model.Settings.Values(user.Setting)
+	if err != nil {
+		return err
+	}
+	render.JSON(w, r, http.StatusOK, settings)
+	return nil
+}
+
+// export: Zip of the personal data of the current user
+func (obj *UserRouter) export(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if !middleware.Unscoped(w, r) {
+		return nil
+	}
+	p, _ := principal.FromContext(ctx)
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Built in memory so a failure still gets an error response
+	var buf bytes.Buffer
+	if err := gdpr.Export(ctx, tx, p.UserID, &buf); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("movies-export-%s.zip", time.Now().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = buf.WriteTo(w)
+	return nil
+}
+
+// delete: Delete the account of the current user
+//
+// The account is closed at once, its personal data is erased after the grace
+// period.
+func (obj *UserRouter) delete(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if !middleware.Unscoped(w, r) {
+		return nil
+	}
+	p, _ := principal.FromContext(ctx)
+
+	var body model.Delete
+	if err := form.ValidateJSON(r.Context(), r.Body, &body); err != nil {
+		return err
+	}
+
+	tx, err := pg.NewTx(ctx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	user, err := sql.Read[model.User]().Where(sql.I("id").Eq(p.UserID)).ForUpdate().FindOne(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if user.PasswordHash.Status != pgtype.Present {
+		return render.NewStatus(http.StatusUnauthorized, cerrors.NewString("Invalid password"))
+	}
+	if ok, err := password.Verify(body.Password, user.PasswordHash.String); err != nil {
+		return err
+	} else if !ok {
+		return render.NewStatus(http.StatusUnauthorized, cerrors.NewString("Invalid password"))
+	}
+	if user.HasTwoFactor() {
+		err := twofactor.Verify(ctx, tx, user, body.Code)
+		if errors.Is(err, twofactor.ErrInvalidCode) {
+			return render.NewStatus(http.StatusUnauthorized, cerrors.NewString("Invalid two-factor code"))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if err := gdpr.Delete(ctx, tx, user); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	session.ClearCookie(w)
+	render.JSON(w, r, http.StatusAccepted, map[string]any{
+		"deleted_at": user.DeletedAt,
+		"erase_at":   user.DeletedAt.Time.Add(config.Account().DeletionGrace()),
+	})
+	return nil
+}
@@ -6,12 +6,16 @@ import (
 	"fmt"
 
 	_ "github.com/jackc/pgx/v4/stdlib"
+	_ "movies/sql/migrations"
 	config "movies/utils/config"
 
 	goose "github.com/pressly/goose/v3"
 )
 
-//go:embed migrations/*.sql
+// Go migrations register themselves from package migrations, goose only runs
+// the ones whose file is also found in the embedded folder
+//
+//go:embed migrations/*.sql migrations/*.go
 var embedMigrations embed.FS
 
 var folderMigrations = "migrations"
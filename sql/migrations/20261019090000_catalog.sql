@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE movies (
+    id             uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    title          text NOT NULL,
+    original_title text NOT NULL DEFAULT '',
+    overview       text NOT NULL DEFAULT '',
+    release_date   date,
+    runtime        integer,
+    created_at     timestamptz NOT NULL DEFAULT NOW(),
+    created_by     uuid,
+    updated_at     timestamptz,
+    updated_by     uuid,
+    deleted_at     timestamptz,
+    deleted_by     uuid
+);
+
+CREATE TABLE people (
+    id         uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name       text NOT NULL,
+    biography  text NOT NULL DEFAULT '',
+    birth_date date,
+    death_date date,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    created_by uuid,
+    updated_at timestamptz,
+    updated_by uuid,
+    deleted_at timestamptz,
+    deleted_by uuid
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE people;
+DROP TABLE movies;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE revisions (
+    id           uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_table text NOT NULL,
+    entity_id    uuid NOT NULL,
+    version      integer NOT NULL,
+    diff         jsonb NOT NULL,
+    updated_by   uuid,
+    created_at   timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT revisions_entity_version_key UNIQUE (entity_table, entity_id, version)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE revisions;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE users (
+    id         uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    username   citext NOT NULL,
+    reputation integer NOT NULL DEFAULT 0,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    created_by uuid,
+    updated_at timestamptz,
+    updated_by uuid,
+    deleted_at timestamptz,
+    deleted_by uuid,
+    CONSTRAINT users_username_key UNIQUE (username)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE users;
+-- +goose StatementEnd
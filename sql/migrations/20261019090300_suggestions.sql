@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE suggestion_status AS ENUM ('pending', 'approved', 'rejected');
+
+CREATE TABLE suggestions (
+    id             uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_table   text NOT NULL,
+    entity_id      uuid NOT NULL,
+    changes        jsonb NOT NULL,
+    comment        text NOT NULL DEFAULT '',
+    status         suggestion_status NOT NULL DEFAULT 'pending',
+    reviewed_at    timestamptz,
+    reviewed_by    uuid REFERENCES users (id),
+    review_comment text NOT NULL DEFAULT '',
+    created_at     timestamptz NOT NULL DEFAULT NOW(),
+    created_by     uuid NOT NULL REFERENCES users (id),
+    updated_at     timestamptz,
+    updated_by     uuid,
+    deleted_at     timestamptz,
+    deleted_by     uuid
+);
+
+CREATE INDEX suggestions_status_idx ON suggestions (status, created_at);
+CREATE INDEX suggestions_entity_idx ON suggestions (entity_table, entity_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE suggestions;
+DROP TYPE suggestion_status;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE movies
+    ADD COLUMN archived_at timestamptz,
+    ADD COLUMN archived_by uuid;
+
+ALTER TABLE people
+    ADD COLUMN archived_at timestamptz,
+    ADD COLUMN archived_by uuid;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE people
+    DROP COLUMN archived_at,
+    DROP COLUMN archived_by;
+
+ALTER TABLE movies
+    DROP COLUMN archived_at,
+    DROP COLUMN archived_by;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN email citext,
+    ADD COLUMN password_hash text,
+    ADD CONSTRAINT users_email_key UNIQUE (email);
+
+CREATE TABLE sessions (
+    id         uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash bytea NOT NULL,
+    ip         text NOT NULL DEFAULT '',
+    user_agent text NOT NULL DEFAULT '',
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT sessions_token_hash_key UNIQUE (token_hash)
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE sessions;
+
+ALTER TABLE users
+    DROP CONSTRAINT users_email_key,
+    DROP COLUMN email,
+    DROP COLUMN password_hash;
+-- +goose StatementEnd
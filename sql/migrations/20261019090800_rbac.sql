@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE roles (
+    id          uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name        text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    CONSTRAINT roles_name_key UNIQUE (name)
+);
+
+CREATE TABLE permissions (
+    id          uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name        text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    CONSTRAINT permissions_name_key UNIQUE (name)
+);
+
+CREATE TABLE role_permissions (
+    role_id       uuid NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id uuid NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE user_roles (
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role_id    uuid NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    created_by uuid,
+    PRIMARY KEY (user_id, role_id)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('editor', 'Edits the catalog directly'),
+    ('moderator', 'Reviews community edits'),
+    ('admin', 'Manages users and restores trashed entities');
+
+INSERT INTO permissions (name, description) VALUES
+    ('movie:write', 'Edit movies'),
+    ('person:write', 'Edit people'),
+    ('revision:revert', 'Revert catalog entities to a previous revision'),
+    ('suggestion:moderate', 'Approve or reject edit suggestions'),
+    ('trash:restore', 'List and restore trashed entities'),
+    ('role:assign', 'Grant and revoke roles');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON
+    (r.name = 'editor' AND p.name IN ('movie:write', 'person:write', 'revision:revert'))
+    OR (r.name = 'moderator' AND p.name IN ('movie:write', 'person:write', 'revision:revert', 'suggestion:moderate'))
+    OR r.name = 'admin';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_roles;
+DROP TABLE role_permissions;
+DROP TABLE permissions;
+DROP TABLE roles;
+-- +goose StatementEnd
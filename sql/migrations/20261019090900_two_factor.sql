@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN totp_secret text,
+    ADD COLUMN totp_enabled_at timestamptz,
+    ADD COLUMN totp_last_counter bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE recovery_codes (
+    id         uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  bytea NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT recovery_codes_user_id_code_hash_key UNIQUE (user_id, code_hash)
+);
+
+ALTER TABLE roles
+    ADD COLUMN requires_two_factor boolean NOT NULL DEFAULT false;
+
+UPDATE roles SET requires_two_factor = true WHERE name IN ('editor', 'admin');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE roles
+    DROP COLUMN requires_two_factor;
+
+DROP TABLE recovery_codes;
+
+ALTER TABLE users
+    DROP COLUMN totp_secret,
+    DROP COLUMN totp_enabled_at,
+    DROP COLUMN totp_last_counter;
+-- +goose StatementEnd
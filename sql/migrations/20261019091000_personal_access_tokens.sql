@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE personal_access_tokens (
+    id           uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id      uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         text NOT NULL,
+    token_hash   bytea NOT NULL,
+    scopes       text[] NOT NULL DEFAULT '{}',
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    last_used_ip text,
+    created_at   timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT personal_access_tokens_token_hash_key UNIQUE (token_hash)
+);
+
+CREATE INDEX personal_access_tokens_user_id_idx ON personal_access_tokens (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE personal_access_tokens;
+-- +goose StatementEnd
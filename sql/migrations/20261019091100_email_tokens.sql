@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN email_verified_at timestamptz;
+
+CREATE TYPE email_token_purpose AS ENUM ('verify_email', 'reset_password');
+
+CREATE TABLE email_tokens (
+    id         uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose    email_token_purpose NOT NULL,
+    email      citext NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX email_tokens_user_id_idx ON email_tokens (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE email_tokens;
+
+DROP TYPE email_token_purpose;
+
+ALTER TABLE users
+    DROP COLUMN email_verified_at;
+-- +goose StatementEnd
@@ -0,0 +1,68 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE oauth_clients (
+    id                 uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    client_id          text NOT NULL,
+    client_secret_hash bytea,
+    name               text NOT NULL,
+    redirect_uris      text[] NOT NULL,
+    scopes             text[] NOT NULL,
+    owner_id           uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at         timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT oauth_clients_client_id_key UNIQUE (client_id)
+);
+
+CREATE TABLE oauth_consents (
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    client_id  uuid NOT NULL REFERENCES oauth_clients (id) ON DELETE CASCADE,
+    scopes     text[] NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz,
+    PRIMARY KEY (user_id, client_id)
+);
+
+CREATE TABLE oauth_codes (
+    id                    uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code_hash             bytea NOT NULL,
+    client_id             uuid NOT NULL REFERENCES oauth_clients (id) ON DELETE CASCADE,
+    user_id               uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    redirect_uri          text NOT NULL,
+    scopes                text[] NOT NULL,
+    code_challenge        text NOT NULL,
+    code_challenge_method text NOT NULL,
+    expires_at            timestamptz NOT NULL,
+    used_at               timestamptz,
+    created_at            timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT oauth_codes_code_hash_key UNIQUE (code_hash)
+);
+
+CREATE TYPE oauth_token_kind AS ENUM ('access', 'refresh');
+
+CREATE TABLE oauth_tokens (
+    id         uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    token_hash bytea NOT NULL,
+    kind       oauth_token_kind NOT NULL,
+    family_id  uuid NOT NULL,
+    client_id  uuid NOT NULL REFERENCES oauth_clients (id) ON DELETE CASCADE,
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    scopes     text[] NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT oauth_tokens_token_hash_key UNIQUE (token_hash)
+);
+
+CREATE INDEX oauth_tokens_family_id_idx ON oauth_tokens (family_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE oauth_tokens;
+
+DROP TYPE oauth_token_kind;
+
+DROP TABLE oauth_codes;
+DROP TABLE oauth_consents;
+DROP TABLE oauth_clients;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE movies
+    ADD COLUMN title_unaccented text NOT NULL DEFAULT '';
+
+ALTER TABLE people
+    ADD COLUMN name_unaccented text NOT NULL DEFAULT '';
+
+CREATE INDEX movies_title_unaccented_idx ON movies USING gin (title_unaccented gin_trgm_ops);
+CREATE INDEX people_name_unaccented_idx ON people USING gin (name_unaccented gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX people_name_unaccented_idx;
+DROP INDEX movies_title_unaccented_idx;
+
+ALTER TABLE people
+    DROP COLUMN name_unaccented;
+
+ALTER TABLE movies
+    DROP COLUMN title_unaccented;
+-- +goose StatementEnd
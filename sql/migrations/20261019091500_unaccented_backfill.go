@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	form "movies/utils/form"
+
+	errors "emperror.dev/errors"
+	goose "github.com/pressly/goose/v3"
+)
+
+// Rows read and updated per statement
+const backfillBatch = 1000
+
+func init() {
+	goose.AddMigrationNoTxContext(upUnaccentedBackfill, downUnaccentedBackfill)
+}
+
+// upUnaccentedBackfill: Fill the unaccented shadow columns with the same
+// transform the conform modifier applies on write
+//
+// Batches commit one by one so rows are not locked for the whole backfill,
+// running it again resumes an interrupted one.
+func upUnaccentedBackfill(ctx context.Context, db *sql.DB) error {
+	if err := backfillUnaccented(ctx, db, "movies", "title"); err != nil {
+		return err
+	}
+	return backfillUnaccented(ctx, db, "people", "name")
+}
+
+// downUnaccentedBackfill: Nothing to undo, the columns are dropped by the
+// previous migration
+func downUnaccentedBackfill(context.Context, *sql.DB) error {
+	return nil
+}
+
+// backfillUnaccented: Set `<column>_unaccented` from `column` on every row, in
+// batches following the id
+func backfillUnaccented(ctx context.Context, db *sql.DB, table, column string) error {
+	sel := "SELECT id::text, " + column + " FROM " + table + " WHERE id > $1::uuid ORDER BY id LIMIT $2"
+	update := "UPDATE " + table + " AS t SET " + column + "_unaccented = v.value" +
+		" FROM unnest($1::text[], $2::text[]) AS v(id, value) WHERE t.id = v.id::uuid"
+
+	after := "00000000-0000-0000-0000-000000000000"
+	for {
+		ids, values, err := unaccentedBatch(ctx, db, sel, after)
+		if err != nil {
+			return err
+		} else if len(ids) == 0 {
+			return nil
+		}
+		if _, err := db.ExecContext(ctx, update, ids, values); err != nil {
+			return errors.WithStack(err)
+		}
+		if len(ids) < backfillBatch {
+			return nil
+		}
+		after = ids[len(ids)-1]
+	}
+}
+
+// unaccentedBatch: Ids and unaccented values of the batch following an id
+func unaccentedBatch(ctx context.Context, db *sql.DB, query, after string) ([]string, []string, error) {
+	rows, err := db.QueryContext(ctx, query, after, backfillBatch)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0, backfillBatch)
+	values := make([]string, 0, backfillBatch)
+	for rows.Next() {
+		var id, value string
+		if err := rows.Scan(&id, &value); err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		unaccented, err := form.RemoveDiacritics(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids, values = append(ids, id), append(values, unaccented)
+	}
+	return ids, values, errors.WithStack(rows.Err())
+}
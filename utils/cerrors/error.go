@@ -50,6 +50,16 @@ func (e *Error) JSON() []byte {
 	return result
 }
 
+// Validation: Whether the chain holds at least one validation error
+func (e *Error) Validation() bool {
+	for elm := e; elm != nil; elm = elm.next {
+		if _, ok := elm.Value.(validationError); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Error) CLI() string {
 	count := 1
 	result := ""
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	viper "github.com/spf13/viper"
+)
+
+type account struct {
+	deletionGrace string
+	eraseInterval string
+}
+
+func (account) namespace() string         { return "Account" }
+func (obj account) key(key string) string { return fmt.Sprintf("%s_%s", obj.namespace(), key) }
+
+func (obj *account) load() {
+	viper.SetDefault(obj.key("DELETION_GRACE"), "30d")
+	viper.SetDefault(obj.key("ERASE_INTERVAL"), "1h")
+
+	obj.deletionGrace = viper.GetString(obj.key("DELETION_GRACE"))
+	obj.eraseInterval = viper.GetString(obj.key("ERASE_INTERVAL"))
+}
+
+// DeletionGrace: Delay between the deletion of an account and its erasure
+func (obj account) DeletionGrace() time.Duration {
+	grace, err := ParseRetention(obj.deletionGrace)
+	if err != nil {
+		log.Fatalf("Invalid account deletion grace: %v", err)
+	}
+	return grace
+}
+
+// EraseInterval: Delay between scheduled erasures, zero when disabled
+func (obj account) EraseInterval() time.Duration {
+	if obj.eraseInterval == "" {
+		return 0
+	}
+	interval, err := ParseRetention(obj.eraseInterval)
+	if err != nil {
+		log.Fatalf("Invalid account erase interval: %v", err)
+	}
+	return interval
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	viper "github.com/spf13/viper"
+)
+
+type auth struct {
+	sessionTTL     string
+	cookieName     string
+	cookieDomain   string
+	cookieSameSite string
+
+	jwtIssuer  string
+	jwtKeys    string
+	jwtKid     string
+	accessTTL  string
+	refreshTTL string
+
+	totpIssuer string
+
+	tokenSecret      string
+	verificationTTL  string
+	passwordResetTTL string
+
+	oauthAccessTTL string
+}
+
+func (auth) namespace() string         { return "Auth" }
+func (obj auth) key(key string) string { return fmt.Sprintf("%s_%s", obj.namespace(), key) }
+
+func (obj *auth) load() {
+	viper.SetDefault(obj.key("SESSION_TTL"), "30d")
+	viper.SetDefault(obj.key("COOKIE_NAME"), "session")
+	viper.SetDefault(obj.key("COOKIE_SAMESITE"), "lax")
+
+	obj.sessionTTL = viper.GetString(obj.key("SESSION_TTL"))
+	obj.cookieName = viper.GetString(obj.key("COOKIE_NAME"))
+	obj.cookieDomain = viper.GetString(obj.key("COOKIE_DOMAIN"))
+	obj.cookieSameSite = viper.GetString(obj.key("COOKIE_SAMESITE"))
+
+	viper.SetDefault(obj.key("JWT_ISSUER"), "movies")
+	viper.SetDefault(obj.key("ACCESS_TTL"), "15m")
+	viper.SetDefault(obj.key("REFRESH_TTL"), "30d")
+
+	obj.jwtIssuer = viper.GetString(obj.key("JWT_ISSUER"))
+	obj.jwtKeys = viper.GetString(obj.key("JWT_KEYS"))
+	obj.jwtKid = viper.GetString(obj.key("JWT_KID"))
+	obj.accessTTL = viper.GetString(obj.key("ACCESS_TTL"))
+	obj.refreshTTL = viper.GetString(obj.key("REFRESH_TTL"))
+
+	viper.SetDefault(obj.key("TOTP_ISSUER"), "movies")
+
+	obj.totpIssuer = viper.GetString(obj.key("TOTP_ISSUER"))
+
+	viper.SetDefault(obj.key("VERIFICATION_TTL"), "2d")
+	viper.SetDefault(obj.key("PASSWORD_RESET_TTL"), "1h")
+
+	obj.tokenSecret = viper.GetString(obj.key("TOKEN_SECRET"))
+	obj.verificationTTL = viper.GetString(obj.key("VERIFICATION_TTL"))
+	obj.passwordResetTTL = viper.GetString(obj.key("PASSWORD_RESET_TTL"))
+
+	viper.SetDefault(obj.key("OAUTH_ACCESS_TTL"), "1h")
+
+	obj.oauthAccessTTL = viper.GetString(obj.key("OAUTH_ACCESS_TTL"))
+}
+
+// SessionTTL: Lifetime of a session
+func (obj auth) SessionTTL() time.Duration {
+	ttl, err := ParseRetention(obj.sessionTTL)
+	if err != nil {
+		log.Fatalf("Invalid session TTL: %v", err)
+	}
+	return ttl
+}
+
+func (obj auth) CookieName() string { return obj.cookieName }
+
+func (obj auth) CookieDomain() string { return obj.cookieDomain }
+
+// CookieSameSite: SameSite policy of the session cookie
+func (obj auth) CookieSameSite() http.SameSite {
+	switch strings.ToLower(obj.cookieSameSite) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// CookieSecure: Cookies are HTTPS only when online, or required by SameSite=None
+func (obj auth) CookieSecure() bool {
+	return IsOnline() || obj.CookieSameSite() == http.SameSiteNoneMode
+}
+
+func (obj auth) JWTIssuer() string { return obj.jwtIssuer }
+
+// JWTKeys: Ed25519 seeds of the signing keys, by key ID
+//
+// Read from `AUTH_JWT_KEYS` as `kid:base64seed` pairs separated by commas.
+// Keys are rotated by adding a new pair, switching `AUTH_JWT_KID` to it,
+// and removing the old pair once the tokens it signed have expired.
+func (obj auth) JWTKeys() map[string][]byte {
+	keys := make(map[string][]byte)
+	for _, pair := range strings.Split(obj.jwtKeys, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kid, seed, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			log.Fatalf("Invalid JWT key `%s`, expected `kid:base64seed`", kid)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(seed)
+		if err != nil || len(decoded) != ed25519.SeedSize {
+			log.Fatalf("Invalid JWT key `%s`, expected a base64 Ed25519 seed", kid)
+		}
+		keys[kid] = decoded
+	}
+	return keys
+}
+
+// JWTKid: ID of the key signing new tokens
+func (obj auth) JWTKid() string { return obj.jwtKid }
+
+// AccessTTL: Lifetime of an access token
+func (obj auth) AccessTTL() time.Duration {
+	ttl, err := ParseRetention(obj.accessTTL)
+	if err != nil {
+		log.Fatalf("Invalid access token TTL: %v", err)
+	}
+	return ttl
+}
+
+// RefreshTTL: Lifetime of a refresh token
+func (obj auth) RefreshTTL() time.Duration {
+	ttl, err := ParseRetention(obj.refreshTTL)
+	if err != nil {
+		log.Fatalf("Invalid refresh token TTL: %v", err)
+	}
+	return ttl
+}
+
+// TOTPIssuer: Name shown by authenticator apps
+func (obj auth) TOTPIssuer() string { return obj.totpIssuer }
+
+// TokenSecret: HMAC key signing the tokens sent by email
+func (obj auth) TokenSecret() string { return obj.tokenSecret }
+
+// VerificationTTL: Lifetime of an email verification token
+func (obj auth) VerificationTTL() time.Duration {
+	ttl, err := ParseRetention(obj.verificationTTL)
+	if err != nil {
+		log.Fatalf("Invalid verification TTL: %v", err)
+	}
+	return ttl
+}
+
+// PasswordResetTTL: Lifetime of a password reset token
+func (obj auth) PasswordResetTTL() time.Duration {
+	ttl, err := ParseRetention(obj.passwordResetTTL)
+	if err != nil {
+		log.Fatalf("Invalid password reset TTL: %v", err)
+	}
+	return ttl
+}
+
+// OAuthAccessTTL: Lifetime of an access token issued to an OAuth client
+func (obj auth) OAuthAccessTTL() time.Duration {
+	ttl, err := ParseRetention(obj.oauthAccessTTL)
+	if err != nil {
+		log.Fatalf("Invalid OAuth access token TTL: %v", err)
+	}
+	return ttl
+}
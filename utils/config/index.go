@@ -28,11 +28,36 @@ func IsOnline() bool { return IsProduction() || IsStaging() || IsDemo() }
 
 func IsTest() bool { return Environment() == "test" }
 
+func Account() account {
+	setup().accountOnce.Do(func() { setup().accountConfig.load() })
+	return setup().accountConfig
+}
+
+func Auth() auth {
+	setup().authOnce.Do(func() { setup().authConfig.load() })
+	return setup().authConfig
+}
+
 func Loki() loki {
 	setup().lokiOnce.Do(func() { setup().lokiConfig.load() })
 	return setup().lokiConfig
 }
 
+func Mail() mail {
+	setup().mailOnce.Do(func() { setup().mailConfig.load() })
+	return setup().mailConfig
+}
+
+func OAuth() oauth {
+	setup().oauthOnce.Do(func() { setup().oauthConfig.load() })
+	return setup().oauthConfig
+}
+
+func Purge() purge {
+	setup().purgeOnce.Do(func() { setup().purgeConfig.load() })
+	return setup().purgeConfig
+}
+
 func PostgreSQL() postgreSQL {
 	setup().pgOnce.Do(func() { setup().pgConfig.load() })
 	return setup().pgConfig
@@ -46,13 +71,33 @@ var once sync.Once
 var config *container
 
 type container struct {
+	// Account
+	accountOnce   sync.Once
+	accountConfig account
+
+	// Auth
+	authOnce   sync.Once
+	authConfig auth
+
 	// Loki
 	lokiOnce   sync.Once
 	lokiConfig loki
 
+	// Mail
+	mailOnce   sync.Once
+	mailConfig mail
+
+	// OAuth
+	oauthOnce   sync.Once
+	oauthConfig oauth
+
 	// PostgreSQL
 	pgOnce   sync.Once
 	pgConfig postgreSQL
+
+	// Purge
+	purgeOnce   sync.Once
+	purgeConfig purge
 }
 
 func setup() *container {
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	viper "github.com/spf13/viper"
+)
+
+type mail struct {
+	backend string
+	from    string
+	baseURL string
+	dir     string
+
+	smtpHost     string
+	smtpPort     int
+	smtpUsername string
+	smtpPassword string
+}
+
+func (mail) namespace() string         { return "Mail" }
+func (obj mail) key(key string) string { return fmt.Sprintf("%s_%s", obj.namespace(), key) }
+
+func (obj *mail) load() {
+	viper.SetDefault(obj.key("FROM"), "Movies <no-reply@localhost>")
+	viper.SetDefault(obj.key("BASE_URL"), "http://localhost:3000")
+	viper.SetDefault(obj.key("SMTP_PORT"), 587)
+
+	obj.backend = viper.GetString(obj.key("BACKEND"))
+	obj.from = viper.GetString(obj.key("FROM"))
+	obj.baseURL = viper.GetString(obj.key("BASE_URL"))
+	obj.dir = viper.GetString(obj.key("DIR"))
+
+	obj.smtpHost = viper.GetString(obj.key("SMTP_HOST"))
+	obj.smtpPort = viper.GetInt(obj.key("SMTP_PORT"))
+	obj.smtpUsername = viper.GetString(obj.key("SMTP_USERNAME"))
+	obj.smtpPassword = viper.GetString(obj.key("SMTP_PASSWORD"))
+}
+
+// Backend: `smtp`, `file` or `console`
+//
+// Defaults to SMTP online and to the console elsewhere.
+func (obj mail) Backend() string {
+	if obj.backend != "" {
+		return strings.ToLower(obj.backend)
+	} else if IsOnline() {
+		return "smtp"
+	}
+	return "console"
+}
+
+func (obj mail) From() string { return obj.from }
+
+// BaseURL: Front-end URL the links of emails point to
+func (obj mail) BaseURL() string { return strings.TrimSuffix(obj.baseURL, "/") }
+
+// Dir: Directory of the `file` backend
+func (obj mail) Dir() string { return obj.dir }
+
+func (obj mail) SMTPHost() string { return obj.smtpHost }
+
+func (obj mail) SMTPPort() int { return obj.smtpPort }
+
+func (obj mail) SMTPUsername() string { return obj.smtpUsername }
+
+func (obj mail) SMTPPassword() string { return obj.smtpPassword }
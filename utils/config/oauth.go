@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+
+	viper "github.com/spf13/viper"
+)
+
+type oauth struct {
+	loginURL string
+}
+
+func (oauth) namespace() string         { return "OAuth" }
+func (obj oauth) key(key string) string { return fmt.Sprintf("%s_%s", obj.namespace(), key) }
+
+func (obj *oauth) load() {
+	viper.SetDefault(obj.key("LOGIN_URL"), "http://localhost:3000/login")
+
+	obj.loginURL = viper.GetString(obj.key("LOGIN_URL"))
+}
+
+// LoginURL: Front-end page signing the user in before the consent screen, it
+// comes back to the `next` query parameter
+func (obj oauth) LoginURL() string { return obj.loginURL }
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	viper "github.com/spf13/viper"
+)
+
+type purge struct {
+	olderThan string
+	interval  string
+	batchSize int
+}
+
+func (purge) namespace() string         { return "Purge" }
+func (obj purge) key(key string) string { return fmt.Sprintf("%s_%s", obj.namespace(), key) }
+
+func (obj *purge) load() {
+	viper.SetDefault(obj.key("OLDER_THAN"), "90d")
+	viper.SetDefault(obj.key("BATCH_SIZE"), 1000)
+
+	obj.olderThan = viper.GetString(obj.key("OLDER_THAN"))
+	obj.interval = viper.GetString(obj.key("INTERVAL"))
+	obj.batchSize = viper.GetInt(obj.key("BATCH_SIZE"))
+}
+
+// Retention: How long soft-deleted rows of a table are kept
+//
+// Read from `PURGE_RETENTION_<TABLE>`, defaults to `PURGE_OLDER_THAN`.
+func (obj purge) Retention(table string) time.Duration {
+	value := viper.GetString(obj.key("RETENTION_" + strings.ToUpper(table)))
+	if value == "" {
+		value = obj.olderThan
+	}
+	retention, err := ParseRetention(value)
+	if err != nil {
+		log.Fatalf("Invalid retention for `%s`: %v", table, err)
+	}
+	return retention
+}
+
+// Interval: Delay between scheduled purges, zero when disabled
+func (obj purge) Interval() time.Duration {
+	if obj.interval == "" {
+		return 0
+	}
+	interval, err := ParseRetention(obj.interval)
+	if err != nil {
+		log.Fatalf("Invalid purge interval: %v", err)
+	}
+	return interval
+}
+
+func (obj purge) BatchSize() int { return obj.batchSize }
+
+// ParseRetention: Parse a duration, with support of days (`90d`) and weeks (`2w`)
+func ParseRetention(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(value, suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("`%s` is not a valid duration", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	return time.ParseDuration(value)
+}
@@ -2,9 +2,13 @@ package form
 
 import (
 	"context"
+	"reflect"
+	"strconv"
+	"strings"
 	"unicode"
 
 	errors "emperror.dev/errors"
+	mold "github.com/go-playground/mold/v4"
 	modifiers "github.com/go-playground/mold/v4/modifiers"
 	pgtype "github.com/jackc/pgtype"
 	lo "github.com/samber/lo"
@@ -13,10 +17,42 @@ import (
 	norm "golang.org/x/text/unicode/norm"
 )
 
-var conform = modifiers.New()
+var conform = initConform()
 
+func initConform() *mold.Transformer {
+	t := modifiers.New()
+	t.Register("nfc", normalizeNFC)
+	t.Register("collapse", collapseSpaces)
+	t.Register("quotes", straightenQuotes)
+	t.Register("titlecase", titleCase)
+	t.Register("truncate", truncate)
+	// Filled by ConformStruct once every other modifier ran
+	t.Register("unaccent", func(context.Context, mold.FieldLevel) error { return nil })
+	return t
+}
+
+// ConformStruct: Apply the `mod:` tags of a struct pointer
 func ConformStruct(obj any) error {
-	return conform.Struct(context.Background(), obj)
+	if err := conform.Struct(context.Background(), obj); err != nil {
+		return err
+	}
+	fillUnaccented(reflect.ValueOf(obj).Elem())
+	return nil
+}
+
+// ConformVar: Apply `mod:` tags to a single value pointer
+func ConformVar(obj any, tags string) error {
+	return conform.Field(context.Background(), obj, tags)
+}
+
+// UnaccentSource: Field an `unaccent=<Field>` modifier is the shadow of
+func UnaccentSource(tags string) (string, bool) {
+	for _, tag := range strings.Split(tags, ",") {
+		if strings.HasPrefix(tag, "unaccent=") {
+			return strings.TrimPrefix(tag, "unaccent="), true
+		}
+	}
+	return "", false
 }
 
 // Conform pgtype.Status
@@ -45,3 +81,141 @@ func RemoveDiacritics(str string) (string, error) {
 	}
 	return result, nil
 }
+
+/*============================================================================*/
+/*=====*                           Modifiers                            *=====*/
+/*============================================================================*/
+
+var quotes = strings.NewReplacer(
+	"\u2018", "'", "\u2019", "'", "\u201A", "'", "\u201B", "'", "\u2032", "'",
+	"\u201C", "\"", "\u201D", "\"", "\u201E", "\"", "\u201F", "\"", "\u2033", "\"",
+)
+
+// Words kept in lower case inside a title, by language
+var minorWords = map[string][]string{
+	"en": {"a", "an", "the", "and", "but", "or", "nor", "for", "so", "yet", "as", "at", "by", "in", "of", "off", "on", "per", "to", "up", "via", "vs"},
+	"fr": {"le", "la", "les", "un", "une", "des", "de", "du", "et", "ou", "à", "au", "aux", "en", "par", "pour", "sur"},
+}
+
+// Elided articles of a title, by language
+var elisions = map[string][]string{
+	"fr": {"l'", "d'"},
+}
+
+// normalizeNFC: `nfc`, Unicode canonical composition
+func normalizeNFC(ctx context.Context, fl mold.FieldLevel) error {
+	return modifyString(fl, norm.NFC.String)
+}
+
+// collapseSpaces: `collapse`, trim and merge runs of whitespace into one space
+func collapseSpaces(ctx context.Context, fl mold.FieldLevel) error {
+	return modifyString(fl, func(str string) string {
+		return strings.Join(strings.Fields(str), " ")
+	})
+}
+
+// straightenQuotes: `quotes`, replace typographic quotes by ASCII ones
+func straightenQuotes(ctx context.Context, fl mold.FieldLevel) error {
+	return modifyString(fl, quotes.Replace)
+}
+
+// titleCase: `titlecase=<lang>`, capitalize words but inner articles,
+// conjunctions and short prepositions, English by default
+//
+// Words already holding a capital are kept as written, like `eXistenZ`.
+func titleCase(ctx context.Context, fl mold.FieldLevel) error {
+	lang := lo.Ternary(fl.Param() == "", "en", fl.Param())
+	return modifyString(fl, func(str string) string {
+		words := strings.Split(str, " ")
+		for i, word := range words {
+			lower := strings.ToLower(word)
+			if word != lower {
+				continue
+			}
+			// Subtitles after a colon start with a capital
+			inner := i > 0 && i < len(words)-1 && !strings.HasSuffix(words[i-1], ":")
+			if inner && lo.Contains(minorWords[lang], lower) {
+				words[i] = lower
+				continue
+			}
+			prefix := ""
+			for _, elision := range elisions[lang] {
+				if i > 0 && strings.HasPrefix(lower, elision) {
+					prefix, word = elision, word[len(elision):]
+				}
+			}
+			words[i] = prefix + capitalize(word)
+		}
+		return strings.Join(words, " ")
+	})
+}
+
+// truncate: `truncate=<length>`, shorten to a number of characters
+func truncate(ctx context.Context, fl mold.FieldLevel) error {
+	length, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		return errors.Errorf("invalid truncate length `%s`", fl.Param())
+	}
+	return modifyString(fl, func(str string) string {
+		return ShortenString(str, length)
+	})
+}
+
+func capitalize(word string) string {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return word
+	}
+	runes[0] = unicode.ToTitle(runes[0])
+	return string(runes)
+}
+
+// modifyString: Apply a transformation to a string or a present pgtype.Text
+func modifyString(fl mold.FieldLevel, fn func(string) string) error {
+	field := fl.Field()
+	if field.Kind() == reflect.String {
+		field.SetString(fn(field.String()))
+	} else if text, ok := field.Interface().(pgtype.Text); ok && text.Status == pgtype.Present {
+		text.String = fn(text.String)
+		field.Set(reflect.ValueOf(text))
+	}
+	return nil
+}
+
+// fillUnaccented: Set every `unaccent=<Field>` shadow from its source field
+func fillUnaccented(obj reflect.Value) {
+	typ := obj.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			fillUnaccented(obj.Field(i))
+			continue
+		}
+		name, ok := UnaccentSource(f.Tag.Get("mod"))
+		if !ok {
+			continue
+		}
+		source := obj.FieldByName(name)
+		if !source.IsValid() {
+			panic("form: unknown unaccent source `" + name + "`")
+		}
+		obj.Field(i).Set(reflect.ValueOf(Unaccent(source.Interface())).Convert(f.Type))
+	}
+}
+
+// Unaccent: Diacritic-free copy of a string or pgtype.Text, other values are
+// returned as is
+func Unaccent(value any) any {
+	switch v := value.(type) {
+	case string:
+		if str, err := RemoveDiacritics(v); err == nil {
+			return str
+		}
+	case pgtype.Text:
+		if str, err := RemoveDiacritics(v.String); err == nil {
+			v.String = str
+		}
+		return v
+	}
+	return value
+}
@@ -0,0 +1,23 @@
+package form
+
+import "testing"
+
+func TestTitleCase(t *testing.T) {
+	tests := []struct {
+		tags, value, want string
+	}{
+		{"titlecase", "the lord of the rings: the return of the king", "The Lord of the Rings: The Return of the King"},
+		{"titlecase", "eXistenZ", "eXistenZ"},
+		{"titlecase", "a man called OVE", "A Man Called OVE"},
+		{"titlecase", "The Shape Of Water", "The Shape Of Water"},
+		{"titlecase=fr", "le fabuleux destin d'amélie poulain", "Le Fabuleux Destin d'Amélie Poulain"},
+	}
+	for _, test := range tests {
+		value := test.value
+		if err := ConformVar(&value, test.tags); err != nil {
+			t.Fatal(err)
+		} else if value != test.want {
+			t.Errorf("%s(%q) = %q, expected %q", test.tags, test.value, value, test.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package form
+
+import (
+	"context"
+	"net/http"
+
+	locales "github.com/go-playground/locales"
+	en "github.com/go-playground/locales/en"
+	fr "github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	validator "github.com/go-playground/validator/v10"
+	en_t "github.com/go-playground/validator/v10/translations/en"
+	fr_t "github.com/go-playground/validator/v10/translations/fr"
+	language "golang.org/x/text/language"
+)
+
+/*============================================================================*/
+/*=====*                             Locales                            *=====*/
+/*============================================================================*/
+
+type locale struct {
+	tag        language.Tag
+	translator locales.Translator
+	defaults   func(v *validator.Validate, trans ut.Translator) error
+}
+
+// supported: Every locale of the validation messages, the first one is the
+// fallback. Custom validators are translated in validator-custom.go.
+var supported = []locale{
+	{tag: language.English, translator: en.New(), defaults: en_t.RegisterDefaultTranslations},
+	{tag: language.French, translator: fr.New(), defaults: fr_t.RegisterDefaultTranslations},
+}
+
+// Locales: Languages of the translations, English first as the fallback
+var Locales = localeTags()
+
+func localeTags() []language.Tag {
+	tags := make([]language.Tag, len(supported))
+	for i, l := range supported {
+		tags[i] = l.tag
+	}
+	return tags
+}
+
+func newUniversal() *ut.UniversalTranslator {
+	translators := make([]locales.Translator, len(supported))
+	for i, l := range supported {
+		translators[i] = l.translator
+	}
+	return ut.New(supported[0].translator, translators...)
+}
+
+// MatchLocale: Closest supported locale of a language tag or Accept-Language
+// header, the fallback when nothing matches
+func MatchLocale(value string) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(value)
+	if err != nil || len(tags) == 0 {
+		return Locales[0]
+	}
+	tag, _, _ := language.NewMatcher(Locales).Match(tags...)
+	base, _ := tag.Base()
+	for _, l := range Locales {
+		if l.String() == base.String() {
+			return l
+		}
+	}
+	return Locales[0]
+}
+
+/*============================================================================*/
+/*=====*                            Context                             *=====*/
+/*============================================================================*/
+
+type ctxKey string
+
+const localeCtxKey ctxKey = "locale"
+
+// WithLocale: Attach a locale to a context
+func WithLocale(ctx context.Context, tag language.Tag) context.Context {
+	return context.WithValue(ctx, localeCtxKey, tag)
+}
+
+// LocaleFromContext: Get the locale of a context, the fallback if none
+func LocaleFromContext(ctx context.Context) language.Tag {
+	if tag, ok := ctx.Value(localeCtxKey).(language.Tag); ok {
+		return tag
+	}
+	return Locales[0]
+}
+
+// NegotiateLocale: Middleware choosing the locale of a request from its
+// Accept-Language header
+func NegotiateLocale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tag := MatchLocale(r.Header.Get("Accept-Language"))
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Set("Content-Language", tag.String())
+		next.ServeHTTP(w, r.WithContext(WithLocale(r.Context(), tag)))
+	})
+}
@@ -0,0 +1,223 @@
+package form
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+
+	cerrors "movies/utils/cerrors"
+
+	mimetype "github.com/gabriel-vasile/mimetype"
+)
+
+// MultipartLimit: Maximum size of a multipart body, files included
+var MultipartLimit int64 = 64 << 20
+
+// Maximum size of the text fields of a multipart body
+const multipartTextLimit = 1 << 20
+
+/*============================================================================*/
+/*=====*                              File                              *=====*/
+/*============================================================================*/
+
+// File: Uploaded file, spooled to a temporary file
+type File struct {
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+	MIME     string `json:"mime"`
+	mime     *mimetype.MIME
+	path     string
+}
+
+var fileType = reflect.TypeOf(File{})
+
+func (f File) String() string { return f.Filename }
+
+// fileValue: File as seen by the validator
+//
+// Tags of struct fields only reach struct level validations, a File is
+// validated through this non-struct type instead.
+type fileValue [1]File
+
+func newFileValue(field reflect.Value) any { return fileValue{field.Interface().(File)} }
+
+func (f fileValue) String() string { return f[0].String() }
+
+func (f fileValue) MarshalJSON() ([]byte, error) { return json.Marshal(f[0]) }
+
+// Open: Read the content of the file
+func (f *File) Open() (*os.File, error) {
+	return os.Open(f.path)
+}
+
+// Is: Whether the sniffed type matches, `type/*` matches a whole family
+func (f *File) Is(expected string) bool {
+	if strings.HasSuffix(expected, "/*") {
+		return strings.HasPrefix(f.MIME, strings.TrimSuffix(expected, "*"))
+	}
+	for m := f.mime; m != nil; m = m.Parent() {
+		if m.Is(expected) {
+			return true
+		}
+	}
+	return false
+}
+
+// Files: Every file spooled by a multipart decoding
+type Files []*File
+
+// RemoveAll: Delete the temporary files, the first failure is returned
+func (files Files) RemoveAll() error {
+	var first error
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+/*============================================================================*/
+/*=====*                           Multipart                            *=====*/
+/*============================================================================*/
+
+// ValidateMultipart: Decode a multipart body into a struct with `form:` tags,
+// then conform and validate it, messages in the locale of the request
+//
+// Text parts are bound like query values, file parts fill `*File` or `[]*File`
+// fields and are streamed to temporary files, parts without a field are
+// skipped. The returned files must be removed by the caller, even on error.
+// The response writer lets an oversized body close the connection.
+func ValidateMultipart(w http.ResponseWriter, r *http.Request, dst any) (Files, error) {
+	obj := reflect.ValueOf(dst)
+	if obj.Kind() != reflect.Pointer || obj.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("form: ValidateMultipart needs a struct pointer, got %T", dst))
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MultipartLimit)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, cerrors.NewString("Multipart body not found")
+	}
+
+	targets := fileFields(obj.Elem())
+	values := url.Values{}
+	var files Files
+	var text int64
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return files, errorMultipart(err)
+		}
+
+		name := part.FormName()
+		if name == "" {
+			continue
+		} else if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, multipartTextLimit-text+1))
+			if err != nil {
+				return files, errorMultipart(err)
+			}
+			if text += int64(len(value)); text > multipartTextLimit {
+				return files, cerrors.NewString("Multipart text fields are too large")
+			}
+			values.Add(name, string(value))
+			continue
+		}
+
+		field, ok := targets[name]
+		if !ok || (field.Kind() == reflect.Pointer && !field.IsNil()) {
+			continue
+		}
+		file, err := spool(part)
+		if file != nil {
+			files = append(files, file)
+		}
+		if err != nil {
+			return files, errorMultipart(err)
+		}
+		if field.Kind() == reflect.Slice {
+			field.Set(reflect.Append(field, reflect.ValueOf(file)))
+		} else {
+			field.Set(reflect.ValueOf(file))
+		}
+	}
+
+	if err := bind(dst, "form", values); err != nil {
+		return files, err
+	}
+	if err := ConformStruct(dst); err != nil {
+		return files, err
+	}
+	return files, ValidateStructContext(r.Context(), dst, false)
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+// fileFields: `*File` and `[]*File` fields by form name, embedded structs included
+func fileFields(obj reflect.Value) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	typ := obj.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := strings.SplitN(f.Tag.Get("form"), ",", 2)[0]
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for k, v := range fileFields(obj.Field(i)) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" || name == "-" || !f.IsExported() {
+			continue
+		}
+		if elem := f.Type; elem.Kind() == reflect.Slice {
+			f.Type = elem.Elem()
+		}
+		if f.Type.Kind() == reflect.Pointer && f.Type.Elem() == fileType {
+			fields[name] = obj.Field(i)
+		}
+	}
+	return fields
+}
+
+// spool: Stream a file part to a temporary file, sniffing its type on the way
+func spool(part *multipart.Part) (*File, error) {
+	head := make([]byte, 3072)
+	n, err := io.ReadFull(part, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer tmp.Close()
+
+	mime := mimetype.Detect(head)
+	file := &File{Filename: part.FileName(), MIME: mime.String(), mime: mime, path: tmp.Name()}
+	written, err := io.Copy(tmp, io.MultiReader(bytes.NewReader(head), part))
+	file.Size = written
+	return file, err
+}
+
+func errorMultipart(err error) error {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return cerrors.NewString("Multipart body is too large, limit is %d bytes", maxBytesError.Limit)
+	}
+	return cerrors.NewString("Multipart Invalid body")
+}
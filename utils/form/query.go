@@ -0,0 +1,192 @@
+package form
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	cerrors "movies/utils/cerrors"
+	pg "movies/utils/pg"
+
+	pgtype "github.com/jackc/pgtype"
+)
+
+/*============================================================================*/
+/*=====*                              Query                             *=====*/
+/*============================================================================*/
+
+// ValidateQuery: Decode the URL query of a request into a struct with `query:`
+// tags, then conform and validate it, messages in the locale of the request
+//
+// Repeated keys fill slice fields. Fields of absent keys keep their value, so
+// defaults can be set before decoding.
+func ValidateQuery(r *http.Request, dst any) error {
+	if err := bind(dst, "query", r.URL.Query()); err != nil {
+		return err
+	}
+	if err := ConformStruct(dst); err != nil {
+		return err
+	}
+	return ValidateStructContext(r.Context(), dst, false)
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+var (
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType        = reflect.TypeOf(time.Time{})
+)
+
+// parsers: Types decoded with the pg helpers instead of their Set method
+var parsers = map[reflect.Type]func(string) (any, error){
+	reflect.TypeOf(pgtype.Date{}):      func(v string) (any, error) { return pg.ParseDate(v) },
+	reflect.TypeOf(pgtype.UUID{}):      func(v string) (any, error) { return pg.ParseUUID(v) },
+	reflect.TypeOf(pgtype.Daterange{}): func(v string) (any, error) { return pg.ParseDaterange(v) },
+	reflect.TypeOf(pgtype.Timestamptz{}): func(v string) (any, error) {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t, err = time.Parse("2006-01-02", v)
+		}
+		return pgtype.Timestamptz{Time: t, Status: pgtype.Present}, err
+	},
+}
+
+// ParseValue: Decode a string into a value of the given type, as a query
+// parameter would be
+func ParseValue(typ reflect.Type, value string) (any, error) {
+	v := reflect.New(typ).Elem()
+	if err := setValue(v, value); err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+// bind: Fill the tagged fields of a struct pointer from string values
+func bind(dst any, tag string, values url.Values) error {
+	ptr := reflect.ValueOf(dst)
+	if ptr.Kind() != reflect.Pointer || ptr.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("form: bind needs a struct pointer, got %T", dst))
+	}
+
+	var er *cerrors.Error
+	bindStruct(ptr.Elem(), tag, values, &er)
+	if er != nil {
+		return er
+	}
+	return nil
+}
+
+func bindStruct(obj reflect.Value, tag string, values url.Values, er **cerrors.Error) {
+	typ := obj.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := strings.SplitN(f.Tag.Get(tag), ",", 2)[0]
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			bindStruct(obj.Field(i), tag, values, er)
+			continue
+		}
+		raw, ok := values[name]
+		if name == "" || name == "-" || !f.IsExported() || !ok {
+			continue
+		}
+		if err := setField(obj.Field(i), raw); err != nil {
+			*er = (*er).Append(cerrors.NewValidation("type", name, fmt.Sprintf("`%s` has an invalid value", name), strings.Join(raw, ",")))
+		}
+	}
+}
+
+// setField: Set a field from one value, or from every value for slices
+func setField(field reflect.Value, raw []string) error {
+	typ := field.Type()
+	if typ.Kind() == reflect.Slice && !isScalar(typ) {
+		slice := reflect.MakeSlice(typ, len(raw), len(raw))
+		for i, v := range raw {
+			if err := setValue(slice.Index(i), v); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setValue(field, raw[len(raw)-1])
+}
+
+// isScalar: Slice types holding a single value
+func isScalar(typ reflect.Type) bool {
+	_, ok := parsers[typ]
+	return ok || typ.Elem().Kind() == reflect.Uint8 || reflect.PointerTo(typ).Implements(textUnmarshaler)
+}
+
+func setValue(field reflect.Value, value string) error {
+	typ := field.Type()
+	if typ.Kind() == reflect.Pointer {
+		elem := reflect.New(typ.Elem())
+		if err := setValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if parse, ok := parsers[typ]; ok {
+		parsed, err := parse(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+	if typ == timeType {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+	if setter, ok := field.Addr().Interface().(pgtype.Value); ok {
+		return setter.Set(value)
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, typ.Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, typ.Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, typ.Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("form: unsupported type %s", typ)
+	}
+	return nil
+}
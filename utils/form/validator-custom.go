@@ -1,12 +1,20 @@
 package form
 
 import (
+	"database/sql/driver"
+	"fmt"
 	"log"
+	"math"
 	"reflect"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	ut "github.com/go-playground/universal-translator"
 	validator "github.com/go-playground/validator/v10"
+	lo "github.com/samber/lo"
+	currency "golang.org/x/text/currency"
 	language "golang.org/x/text/language"
 )
 
@@ -20,28 +28,47 @@ type translation struct {
 }
 
 type customValidator struct {
-	Name        string
+	Name string
+	// Tag used in struct tags instead of the name, to replace a baked-in alias
+	// which would otherwise take precedence over the validator
+	Alias       string
 	Validate    func(fl validator.FieldLevel) bool
 	Translation []translation
 }
 
+// Register: Declare the validator and its translations, locales without a
+// translation get the one of the fallback locale
 func (v customValidator) Register(vld *validator.Validate) {
 	if err := vld.RegisterValidation(v.Name, v.Validate); err != nil {
 		log.Fatal(err)
 	}
+	name := v.Name
+	if v.Alias != "" {
+		vld.RegisterAlias(v.Alias, v.Name)
+		name = v.Alias
+	}
 
+	texts := make(map[language.Tag]string, len(v.Translation))
 	for _, t := range v.Translation {
+		texts[t.language] = t.translation
+	}
+
+	for _, tag := range Locales {
+		text, ok := texts[tag]
+		if !ok {
+			text = texts[Locales[0]]
+		}
 		err := vld.RegisterTranslation(
-			v.Name,
-			translator(t.language),
+			name,
+			translator(tag),
 			func(ut ut.Translator) (err error) {
-				if err = ut.Add(v.Name, t.translation, true); err != nil {
+				if err = ut.Add(name, text, true); err != nil {
 					return
 				}
 				return
 			},
 			func(ut ut.Translator, fe validator.FieldError) string {
-				t, err := ut.T(fe.Tag(), reflect.ValueOf(fe.Value()).String(), fe.Param())
+				t, err := ut.T(fe.Tag(), displayValue(fe.Value()), fe.Param())
 				if err != nil {
 					return fe.(error).Error()
 				}
@@ -54,6 +81,24 @@ func (v customValidator) Register(vld *validator.Validate) {
 	}
 }
 
+// displayValue: Text of a value in a translation
+func displayValue(value any) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		if v, err := valuer.Value(); err == nil {
+			value = v
+		}
+	}
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case time.Time:
+		return v.Format("2006-01-02")
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
+
 /*============================================================================*/
 /*=====*                           Alphanumdot                          *=====*/
 /*============================================================================*/
@@ -69,6 +114,9 @@ func Alphanumdot(v *validator.Validate) {
 		Translation: []translation{{
 			language:    language.English,
 			translation: "`{0}` is not valid, must be alphanumeric & dot characters only",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas valide, seuls les caractères alphanumériques et les points sont autorisés",
 		}},
 	}
 
@@ -86,6 +134,9 @@ func Hexanumdot(v *validator.Validate) {
 		Translation: []translation{{
 			language:    language.English,
 			translation: "`{0}` is not valid, must be hexanumeric & dot characters only",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas valide, seuls les caractères hexadécimaux et les points sont autorisés",
 		}},
 	}
 
@@ -107,8 +158,293 @@ func Enum(v *validator.Validate) {
 		Translation: []translation{{
 			language:    language.English,
 			translation: "`{0}` is not a valid enum",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas une valeur d'énumération valide",
+		}},
+	}
+
+	cv.Register(v)
+}
+
+/*============================================================================*/
+/*=====*                             Catalog                            *=====*/
+/*============================================================================*/
+
+// First film ever shot, Passage de Vénus
+const firstReleaseYear = 1874
+
+// Longest runtime accepted, in minutes
+const maxRuntime = 1440
+
+var (
+	imdbTitleRegex = regexp.MustCompile(`^tt[0-9]{7,8}$`)
+	imdbNameRegex  = regexp.MustCompile(`^nm[0-9]{7,8}$`)
+	lowerRegex     = regexp.MustCompile(`^[a-z]{2}$`)
+	upperRegex     = regexp.MustCompile(`^[A-Z]{2,3}$`)
+	digitsRegex    = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// Codes known to x/text but not assigned by the standards
+var (
+	// Withdrawn ISO 639-1 codes
+	withdrawnLanguages = []string{"in", "iw", "ji", "jw", "mo"}
+	// ISO 3166-1 user-assigned, exceptionally and transitionally reserved codes
+	reservedCountries = []string{
+		"XK", "AC", "CP", "DG", "EA", "EU", "EZ", "FX", "IC", "SU", "TA", "UK", "UN",
+		"AN", "BU", "CS", "DD", "NT", "TP", "YU", "ZR",
+	}
+	// ISO 4217 testing and no-currency codes
+	nonCurrencies = []string{"XTS", "XXX"}
+)
+
+// Catalog: Validators of catalog identifiers and values
+func Catalog(v *validator.Validate) {
+	validators := []customValidator{{
+		Name: "imdb_title",
+		Validate: func(fl validator.FieldLevel) bool {
+			return imdbTitleRegex.MatchString(fl.Field().String())
+		},
+		Translation: []translation{{
+			language:    language.English,
+			translation: "`{0}` is not a valid IMDb title ID, expected `tt` followed by 7 or 8 digits",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas un identifiant de titre IMDb valide, attendu `tt` suivi de 7 ou 8 chiffres",
+		}},
+	}, {
+		Name: "imdb_name",
+		Validate: func(fl validator.FieldLevel) bool {
+			return imdbNameRegex.MatchString(fl.Field().String())
+		},
+		Translation: []translation{{
+			language:    language.English,
+			translation: "`{0}` is not a valid IMDb name ID, expected `nm` followed by 7 or 8 digits",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas un identifiant de personne IMDb valide, attendu `nm` suivi de 7 ou 8 chiffres",
+		}},
+	}, {
+		Name: "tmdb_id",
+		Validate: func(fl validator.FieldLevel) bool {
+			id, ok := fieldInt(fl.Field())
+			return ok && id > 0 && id <= math.MaxInt32
+		},
+		Translation: []translation{{
+			language:    language.English,
+			translation: "`{0}` is not a valid TMDB ID, must be a positive integer",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas un identifiant TMDB valide, doit être un entier positif",
+		}},
+	}, {
+		Name: "language_code",
+		Validate: func(fl validator.FieldLevel) bool {
+			code := fl.Field().String()
+			if !lowerRegex.MatchString(code) {
+				return false
+			}
+			_, err := language.ParseBase(code)
+			return err == nil && !lo.Contains(withdrawnLanguages, code)
+		},
+		Translation: []translation{{
+			language:    language.English,
+			translation: "`{0}` is not a valid ISO 639-1 language code",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas un code de langue ISO 639-1 valide",
+		}},
+	}, {
+		// The baked-in `country_code` also accepts alpha-3 and numeric codes
+		Name:  "country_alpha2",
+		Alias: "country_code",
+		Validate: func(fl validator.FieldLevel) bool {
+			code := fl.Field().String()
+			if len(code) != 2 || !upperRegex.MatchString(code) {
+				return false
+			}
+			region, err := language.ParseRegion(code)
+			return err == nil && region.IsCountry() && !lo.Contains(reservedCountries, code)
+		},
+		Translation: []translation{{
+			language:    language.English,
+			translation: "`{0}` is not a valid ISO 3166-1 alpha-2 country code",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas un code pays ISO 3166-1 alpha-2 valide",
+		}},
+	}, {
+		Name: "currency_code",
+		Validate: func(fl validator.FieldLevel) bool {
+			code := fl.Field().String()
+			if len(code) != 3 || !upperRegex.MatchString(code) {
+				return false
+			}
+			_, err := currency.ParseISO(code)
+			return err == nil && !lo.Contains(nonCurrencies, code)
+		},
+		Translation: []translation{{
+			language:    language.English,
+			translation: "`{0}` is not a valid ISO 4217 currency code",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas un code de devise ISO 4217 valide",
+		}},
+	}, {
+		Name: "release_year",
+		Validate: func(fl validator.FieldLevel) bool {
+			var year int64
+			if t, ok := fl.Field().Interface().(time.Time); ok {
+				year = int64(t.Year())
+			} else if y, ok := fieldInt(fl.Field()); ok {
+				year = y
+			} else {
+				return false
+			}
+			return year >= firstReleaseYear && year <= int64(time.Now().Year()+10)
+		},
+		Translation: []translation{{
+			language:    language.English,
+			translation: "`{0}` is not a plausible release year",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas une année de sortie plausible",
+		}},
+	}, {
+		Name: "runtime",
+		Validate: func(fl validator.FieldLevel) bool {
+			minutes, ok := fieldInt(fl.Field())
+			return ok && minutes > 0 && minutes <= maxRuntime
+		},
+		Translation: []translation{{
+			language:    language.English,
+			translation: "`{0}` is not a plausible runtime, must be between 1 and 1440 minutes",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas une durée plausible, doit être comprise entre 1 et 1440 minutes",
+		}},
+	}, {
+		Name: "barcode",
+		Validate: func(fl validator.FieldLevel) bool {
+			return validBarcode(fl.Field().String())
+		},
+		Translation: []translation{{
+			language:    language.English,
+			translation: "`{0}` is not a valid EAN or UPC barcode",
+		}, {
+			language:    language.French,
+			translation: "`{0}` n'est pas un code-barres EAN ou UPC valide",
+		}},
+	}}
+
+	for _, cv := range validators {
+		cv.Register(v)
+	}
+}
+
+// fieldInt: Integer of an integer field or of a string of digits
+func fieldInt(field reflect.Value) (int64, bool) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if field.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(field.Uint()), true
+	case reflect.String:
+		if !digitsRegex.MatchString(field.String()) {
+			return 0, false
+		}
+		value, err := strconv.ParseInt(field.String(), 10, 64)
+		return value, err == nil
+	}
+	return 0, false
+}
+
+// validBarcode: EAN-8, UPC-A or EAN-13 with a valid GS1 check digit
+func validBarcode(code string) bool {
+	if !digitsRegex.MatchString(code) || (len(code) != 8 && len(code) != 12 && len(code) != 13) {
+		return false
+	}
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
+
+/*============================================================================*/
+/*=====*                              Files                             *=====*/
+/*============================================================================*/
+
+// MaxSize: Size limit of an uploaded file, e.g. `maxsize=5MB`
+func MaxSize(v *validator.Validate) {
+	cv := customValidator{
+		Name: "maxsize",
+		Validate: func(fl validator.FieldLevel) bool {
+			file, ok := fl.Field().Interface().(fileValue)
+			return ok && file[0].Size <= parseSize(fl.Param())
+		},
+		Translation: []translation{{
+			language:    language.English,
+			translation: "`{0}` is too large, must be at most {1}",
+		}, {
+			language:    language.French,
+			translation: "`{0}` est trop volumineux, la taille maximale est {1}",
 		}},
 	}
 
 	cv.Register(v)
 }
+
+// Mime: Sniffed types accepted for an uploaded file, space separated, e.g.
+// `mime=image/png image/jpeg` or `mime=image/*`
+func Mime(v *validator.Validate) {
+	cv := customValidator{
+		Name: "mime",
+		Validate: func(fl validator.FieldLevel) bool {
+			file, ok := fl.Field().Interface().(fileValue)
+			if !ok {
+				return false
+			}
+			for _, expected := range strings.Fields(fl.Param()) {
+				if file[0].Is(expected) {
+					return true
+				}
+			}
+			return false
+		},
+		Translation: []translation{{
+			language:    language.English,
+			translation: "`{0}` has an unsupported type, must be one of {1}",
+		}, {
+			language:    language.French,
+			translation: "`{0}` a un type non pris en charge, types acceptés : {1}",
+		}},
+	}
+
+	cv.Register(v)
+}
+
+var sizeUnits = map[string]int64{"B": 1, "KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30}
+
+// parseSize: Bytes of a size with a binary unit, panics on invalid tags
+func parseSize(param string) int64 {
+	param = strings.ToUpper(strings.TrimSpace(param))
+	number := strings.TrimRight(param, "KMGB")
+	unit := strings.TrimPrefix(param, number)
+	if unit == "" {
+		unit = "B"
+	}
+	size, err := strconv.ParseInt(number, 10, 64)
+	multiplier, ok := sizeUnits[unit]
+	if err != nil || !ok {
+		panic(fmt.Sprintf("form: invalid size `%s`", param))
+	}
+	return size * multiplier
+}
@@ -0,0 +1,135 @@
+package form
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	cerrors "movies/utils/cerrors"
+
+	language "golang.org/x/text/language"
+)
+
+func TestCatalog(t *testing.T) {
+	nextYears := time.Now().Year() + 11
+
+	tests := []struct {
+		tag     string
+		valid   []any
+		invalid []any
+		en, fr  string
+	}{{
+		tag:     "imdb_title",
+		valid:   []any{"tt0111161", "tt10872600"},
+		invalid: []any{"tt011116", "nm0000151", "tt123456789", "TT0111161", ""},
+		en:      "`tt011116` is not a valid IMDb title ID, expected `tt` followed by 7 or 8 digits",
+		fr:      "`tt011116` n'est pas un identifiant de titre IMDb valide, attendu `tt` suivi de 7 ou 8 chiffres",
+	}, {
+		tag:     "imdb_name",
+		valid:   []any{"nm0000151", "nm12345678"},
+		invalid: []any{"nm000015", "tt0111161", "nm0000151x"},
+		en:      "`nm000015` is not a valid IMDb name ID, expected `nm` followed by 7 or 8 digits",
+		fr:      "`nm000015` n'est pas un identifiant de personne IMDb valide, attendu `nm` suivi de 7 ou 8 chiffres",
+	}, {
+		tag:     "tmdb_id",
+		valid:   []any{550, int64(2147483647), "278", uint(1)},
+		invalid: []any{0, -1, int64(2147483648), "abc", "-5", 1.5},
+		en:      "`0` is not a valid TMDB ID, must be a positive integer",
+		fr:      "`0` n'est pas un identifiant TMDB valide, doit être un entier positif",
+	}, {
+		tag:     "language_code",
+		valid:   []any{"en", "fr", "ja"},
+		invalid: []any{"EN", "eng", "e", "e1", "iw", "in", "mo"},
+		en:      "`EN` is not a valid ISO 639-1 language code",
+		fr:      "`EN` n'est pas un code de langue ISO 639-1 valide",
+	}, {
+		tag:     "country_code",
+		valid:   []any{"FR", "US", "JP"},
+		invalid: []any{"fr", "FRA", "ZZ", "EU", "XK", "AC", "UN"},
+		en:      "`fr` is not a valid ISO 3166-1 alpha-2 country code",
+		fr:      "`fr` n'est pas un code pays ISO 3166-1 alpha-2 valide",
+	}, {
+		tag:     "currency_code",
+		valid:   []any{"EUR", "USD", "JPY"},
+		invalid: []any{"eur", "EU", "EURO", "XYZ", "XXX", "XTS"},
+		en:      "`eur` is not a valid ISO 4217 currency code",
+		fr:      "`eur` n'est pas un code de devise ISO 4217 valide",
+	}, {
+		tag:     "release_year",
+		valid:   []any{1874, 1994, "2001", time.Date(1999, 3, 31, 0, 0, 0, 0, time.UTC)},
+		invalid: []any{1873, nextYears, "19x4", time.Date(1800, 1, 1, 0, 0, 0, 0, time.UTC)},
+		en:      "`1873` is not a plausible release year",
+		fr:      "`1873` n'est pas une année de sortie plausible",
+	}, {
+		tag:     "runtime",
+		valid:   []any{1, 142, 1440, "90"},
+		invalid: []any{0, 1441, -90, "1h30"},
+		en:      "`0` is not a plausible runtime, must be between 1 and 1440 minutes",
+		fr:      "`0` n'est pas une durée plausible, doit être comprise entre 1 et 1440 minutes",
+	}, {
+		tag: "barcode",
+		// EAN-13, UPC-A, EAN-8
+		valid: []any{"4006381333931", "036000291452", "96385074", "5901234123457"},
+		// Check digit off by one, then wrong lengths and non digits
+		invalid: []any{"4006381333932", "036000291453", "96385075", "5901234123458", "40063813339", "400638133393a"},
+		en:      "`4006381333932` is not a valid EAN or UPC barcode",
+		fr:      "`4006381333932` n'est pas un code-barres EAN ou UPC valide",
+	}}
+
+	en := WithLocale(context.Background(), language.English)
+	fr := WithLocale(context.Background(), language.French)
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			for _, value := range test.valid {
+				if err := ValidateVarContext(en, "value", value, test.tag); err != nil {
+					t.Errorf("%v: unexpected error %v", value, err)
+				}
+			}
+			for _, value := range test.invalid {
+				if err := ValidateVarContext(en, "value", value, test.tag); err == nil {
+					t.Errorf("%v: expected an error", value)
+				}
+			}
+
+			for ctx, expected := range map[context.Context]string{en: test.en, fr: test.fr} {
+				err := ValidateVarContext(ctx, "value", test.invalid[0], test.tag)
+				if got := validationMessage(t, err, test.tag); got != expected {
+					t.Errorf("message = %q, want %q", got, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestValidBarcode(t *testing.T) {
+	// Every wrong check digit of a valid EAN-13 must be rejected
+	base := "400638133393"
+	for digit := 0; digit <= 9; digit++ {
+		code := base + strconv.Itoa(digit)
+		if got := validBarcode(code); got != (digit == 1) {
+			t.Errorf("validBarcode(%s) = %v", code, got)
+		}
+	}
+}
+
+// validationMessage: Message of the single validation error of a tag
+func validationMessage(t *testing.T, err error, tag string) string {
+	t.Helper()
+	cerr := cerrors.IsError(err)
+	if cerr == nil {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	var errs []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(cerr.JSON(), &errs); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Code != tag {
+		t.Fatalf("expected a single `%s` error, got %s", tag, cerr.JSON())
+	}
+	return errs[0].Message
+}
@@ -1,6 +1,7 @@
 package form
 
 import (
+	"context"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
@@ -13,12 +14,8 @@ import (
 
 	cerrors "movies/utils/cerrors"
 
-	en "github.com/go-playground/locales/en"
-	fr "github.com/go-playground/locales/fr"
 	ut "github.com/go-playground/universal-translator"
 	validator "github.com/go-playground/validator/v10"
-	en_t "github.com/go-playground/validator/v10/translations/en"
-	fr_t "github.com/go-playground/validator/v10/translations/fr"
 	pgtype "github.com/jackc/pgtype"
 	numeric "github.com/jackc/pgtype/ext/shopspring-numeric"
 	language "golang.org/x/text/language"
@@ -29,31 +26,38 @@ import (
 /*===========================================================================*/
 
 var (
-	validate *validator.Validate = initValidator()
+	universal *ut.UniversalTranslator = newUniversal()
+	validate  *validator.Validate     = initValidator()
 )
 
+// translator: Get the shared translator of a language
+//
+// Translations are registered per translator, it must not be recreated.
 func translator(lang language.Tag) ut.Translator {
-	uni := ut.New(en.New(), en.New(), fr.New())
-	trans, _ := uni.GetTranslator(lang.String())
+	trans, _ := universal.GetTranslator(lang.String())
 	return trans
 }
 
 func initValidator() *validator.Validate {
 	v := validator.New()
 
-	if err := en_t.RegisterDefaultTranslations(v, translator(language.English)); err != nil {
-		log.Fatal(err)
-	}
-	if err := fr_t.RegisterDefaultTranslations(v, translator(language.French)); err != nil {
-		log.Fatal(err)
+	for _, l := range supported {
+		if err := l.defaults(v, translator(l.tag)); err != nil {
+			log.Fatal(err)
+		}
 	}
 
+	// Name fields as they are sent: JSON body, then query or form values
 	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
-		if name == "-" {
-			return ""
+		for _, tag := range []string{"json", "query", "form"} {
+			name := strings.SplitN(fld.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			} else if name != "" {
+				return name
+			}
 		}
-		return name
+		return ""
 	})
 
 	// Register pgtype
@@ -68,6 +72,8 @@ func initValidator() *validator.Validate {
 			return nil
 		},
 		pgtype.Bool{}, pgtype.Date{}, pgtype.Daterange{},
+		pgtype.Text{}, pgtype.Int2{}, pgtype.Int4{}, pgtype.Int8{},
+		pgtype.Float4{}, pgtype.Float8{},
 		pgtype.JSON{}, pgtype.JSONB{},
 		pgtype.UUID{},
 		pgtype.Timestamp{}, pgtype.Timestamptz{},
@@ -75,10 +81,16 @@ func initValidator() *validator.Validate {
 		pgtype.Point{}, numeric.Numeric{},
 	)
 
+	// Register uploaded files
+	v.RegisterCustomTypeFunc(newFileValue, File{})
+
 	// Custom validation
 	Alphanumdot(v)
 	Hexanumdot(v)
 	Enum(v)
+	Catalog(v)
+	MaxSize(v)
+	Mime(v)
 
 	return v
 }
@@ -91,18 +103,54 @@ func GetValidator() *validator.Validate {
 	return validate
 }
 
+// ValidateStruct: Validate a struct, messages in the fallback locale
 func ValidateStruct(obj any, fieldPath bool) error {
-	lang := language.English
-	validator := GetValidator()
-	err := validator.Struct(obj)
-	return formatErrors(lang, err, fieldPath)
+	return validateStruct(Locales[0], obj, fieldPath)
 }
 
-func ValidateJSON(r io.Reader, obj any) error {
+// ValidateStructContext: Validate a struct, messages in the locale of the context
+func ValidateStructContext(ctx context.Context, obj any, fieldPath bool) error {
+	return validateStruct(LocaleFromContext(ctx), obj, fieldPath)
+}
+
+// ValidateVar: Validate a single value, reported under the given field name
+func ValidateVar(field string, value any, tag string) error {
+	return ValidateVarContext(context.Background(), field, value, tag)
+}
+
+// ValidateVarContext: Validate a single value, messages in the locale of the context
+func ValidateVarContext(ctx context.Context, field string, value any, tag string) error {
+	if value == nil {
+		return nil
+	}
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: reflect.TypeOf(value),
+		Tag:  reflect.StructTag(fmt.Sprintf(`json:%q validate:%q`, field, tag)),
+	}})
+	obj := reflect.New(typ)
+	obj.Elem().Field(0).Set(reflect.ValueOf(value))
+	return ValidateStructContext(ctx, obj.Interface(), false)
+}
+
+// DecodeJSON: Decode a JSON body without validation
+func DecodeJSON(r io.Reader, obj any) error {
 	if err := json.NewDecoder(r).Decode(obj); err != nil {
 		return errorJSON(err)
 	}
-	if e := ValidateStruct(obj, false); e != nil {
+	return nil
+}
+
+// ValidateJSON: Decode, conform and validate a JSON body, messages in the locale
+// of the context
+func ValidateJSON(ctx context.Context, r io.Reader, obj any) error {
+	if err := DecodeJSON(r, obj); err != nil {
+		return err
+	}
+	if err := ConformStruct(obj); err != nil {
+		return err
+	}
+	if e := ValidateStructContext(ctx, obj, false); e != nil {
 		return e
 	}
 	return nil
@@ -147,7 +195,12 @@ func errorJSON(err error) error {
 		return cerrors.NewString(fmt.Sprintf("JSON Cannot parse `%s`", strings.Trim(parseError.Value, "\"")))
 	}
 
-	panic(err)
+	return cerrors.NewString("JSON Invalid body")
+}
+
+func validateStruct(lang language.Tag, obj any, fieldPath bool) error {
+	err := GetValidator().Struct(obj)
+	return formatErrors(lang, err, fieldPath)
 }
 
 func formatErrors(lang language.Tag, errs error, fieldPath bool) error {
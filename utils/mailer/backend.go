@@ -0,0 +1,123 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	config "movies/utils/config"
+	logger "movies/utils/logger"
+
+	errors "emperror.dev/errors"
+)
+
+/*============================================================================*/
+/*=====*                              SMTP                              *=====*/
+/*============================================================================*/
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+}
+
+// NewSMTP: Mailer relaying through an SMTP server, with STARTTLS when offered
+func NewSMTP(host string, port int, username, password string) Mailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpMailer{addr: fmt.Sprintf("%s:%d", host, port), auth: auth}
+}
+
+func (obj *smtpMailer) Send(ctx context.Context, msg Message) error {
+	from, err := parseAddress(config.Mail().From())
+	if err != nil {
+		return err
+	}
+	to, err := parseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+	data, err := encode(config.Mail().From(), msg)
+	if err != nil {
+		return err
+	}
+	return errors.WithStack(smtp.SendMail(obj.addr, obj.auth, from, []string{to}, data))
+}
+
+/*============================================================================*/
+/*=====*                              File                              *=====*/
+/*============================================================================*/
+
+type fileMailer struct {
+	dir string
+}
+
+// NewFile: Mailer writing `.eml` files to a directory, for development
+func NewFile(dir string) Mailer {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "movies-mail")
+	}
+	return &fileMailer{dir: dir}
+}
+
+func (obj *fileMailer) Send(ctx context.Context, msg Message) error {
+	data, err := encode(config.Mail().From(), msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(obj.dir, 0o755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), sanitize(msg.To))
+	path := filepath.Join(obj.dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return errors.WithStack(err)
+	}
+	logger.With("path", path).Info(ctx, "Mail to %s written", msg.To)
+	return nil
+}
+
+/*============================================================================*/
+/*=====*                            Console                             *=====*/
+/*============================================================================*/
+
+type consoleMailer struct{}
+
+// NewConsole: Mailer printing the text part of messages, for development
+func NewConsole() Mailer {
+	return consoleMailer{}
+}
+
+func (consoleMailer) Send(ctx context.Context, msg Message) error {
+	_, err := fmt.Fprintf(os.Stdout, "\n===== Mail to %s =====\nSubject: %s\n\n%s\n=====\n", msg.To, msg.Subject, msg.Text)
+	return errors.WithStack(err)
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+// parseAddress: Bare address of `Name <address>`
+func parseAddress(value string) (string, error) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return "", errors.WrapIf(err, "invalid email address")
+	}
+	return addr.Address, nil
+}
+
+func sanitize(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, value)
+}
@@ -0,0 +1,126 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	config "movies/utils/config"
+
+	errors "emperror.dev/errors"
+)
+
+/*============================================================================*/
+/*=====*                            Mailer                              *=====*/
+/*============================================================================*/
+
+// Message: Email with a text and an optional HTML alternative
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer: Backend delivering messages
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var (
+	mailer     Mailer
+	mailerOnce sync.Once
+)
+
+// Default: Mailer of the configured backend
+func Default() Mailer {
+	mailerOnce.Do(func() {
+		switch backend := config.Mail().Backend(); backend {
+		case "smtp":
+			mailer = NewSMTP(config.Mail().SMTPHost(), config.Mail().SMTPPort(), config.Mail().SMTPUsername(), config.Mail().SMTPPassword())
+		case "file":
+			mailer = NewFile(config.Mail().Dir())
+		case "console":
+			mailer = NewConsole()
+		default:
+			log.Fatalf("Invalid mail backend `%s`, expected smtp, file or console", backend)
+		}
+	})
+	return mailer
+}
+
+// Send: Deliver a message with the default mailer
+func Send(ctx context.Context, msg Message) error {
+	return Default().Send(ctx, msg)
+}
+
+/*============================================================================*/
+/*=====*                              MIME                              *=====*/
+/*============================================================================*/
+
+// encode: RFC 5322 message, multipart/alternative when there is HTML
+func encode(from string, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	headers := []string{
+		"From: " + from,
+		"To: " + msg.To,
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: " + messageID(from),
+		"MIME-Version: 1.0",
+	}
+	for _, header := range headers {
+		buf.WriteString(header + "\r\n")
+	}
+
+	if msg.HTML == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+		buf.WriteString(msg.Text)
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	buf.WriteString("Content-Type: multipart/alternative; boundary=" + parts.Boundary() + "\r\n\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if _, err := w.Write([]byte(part.body)); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func messageID(from string) string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	domain := "localhost"
+	if addr, err := parseAddress(from); err == nil {
+		if _, host, ok := strings.Cut(addr, "@"); ok {
+			domain = host
+		}
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(buf), domain)
+}
@@ -0,0 +1,92 @@
+package mailer
+
+import (
+	"bytes"
+	htmlTemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	textTemplate "text/template"
+
+	form "movies/utils/form"
+
+	errors "emperror.dev/errors"
+	language "golang.org/x/text/language"
+)
+
+// Templates: Localized emails, read from `<locale>/<name>.txt` and
+// `<locale>/<name>.html`
+//
+// The text template defines the subject in a `subject` block. Every locale of
+// `form.Locales` must provide every email. Blocks shared by the emails of a
+// locale, like the wording of durations, live in `<locale>/*.tmpl`.
+type Templates struct {
+	text map[string]*textTemplate.Template
+	html map[string]*htmlTemplate.Template
+}
+
+// NewTemplates: Parse the templates of a file system
+func NewTemplates(fsys fs.FS, names ...string) (*Templates, error) {
+	templates := &Templates{
+		text: map[string]*textTemplate.Template{},
+		html: map[string]*htmlTemplate.Template{},
+	}
+	for _, locale := range form.Locales {
+		shared, err := fs.Glob(fsys, path.Join(locale.String(), "*.tmpl"))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, name := range names {
+			key := templateKey(locale, name)
+
+			textFiles := append([]string{path.Join(locale.String(), name+".txt")}, shared...)
+			text, err := textTemplate.ParseFS(fsys, textFiles...)
+			if err != nil {
+				return nil, errors.WrapIff(err, "email `%s`", key)
+			}
+			if text.Lookup("subject") == nil {
+				return nil, errors.Errorf("email `%s` has no subject", key)
+			}
+			htmlFiles := append([]string{path.Join(locale.String(), name+".html")}, shared...)
+			html, err := htmlTemplate.ParseFS(fsys, htmlFiles...)
+			if err != nil {
+				return nil, errors.WrapIff(err, "email `%s`", key)
+			}
+
+			templates.text[key] = text
+			templates.html[key] = html
+		}
+	}
+	return templates, nil
+}
+
+// Render: Build the message of an email in a locale, English by default
+func (obj *Templates) Render(name, locale, to string, data any) (Message, error) {
+	key := templateKey(form.MatchLocale(locale), name)
+	text, ok := obj.text[key]
+	if !ok {
+		return Message{}, errors.Errorf("unknown email `%s`", name)
+	}
+
+	var subject, body, html bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, errors.WithStack(err)
+	}
+	if err := text.Execute(&body, data); err != nil {
+		return Message{}, errors.WithStack(err)
+	}
+	if err := obj.html[key].Execute(&html, data); err != nil {
+		return Message{}, errors.WithStack(err)
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+func templateKey(locale language.Tag, name string) string {
+	return locale.String() + "/" + name
+}
@@ -4,6 +4,7 @@ import (
 	errors "emperror.dev/errors"
 	pgxscan "github.com/georgysavva/scany/pgxscan"
 	pgconn "github.com/jackc/pgconn"
+	pgerrcode "github.com/jackc/pgerrcode"
 	pgx "github.com/jackc/pgx/v4"
 )
 
@@ -36,3 +37,9 @@ func IsErrConstraint(err error, code, constraint string) bool {
 	pgErr := HavePGErr(err)
 	return pgErr != nil && pgErr.Code == code && pgErr.ConstraintName == constraint
 }
+
+// IsConstraintViolation: Any integrity constraint violation (unique, foreign key, check, ...)
+func IsConstraintViolation(err error) bool {
+	pgErr := HavePGErr(err)
+	return pgErr != nil && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code)
+}
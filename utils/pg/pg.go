@@ -111,6 +111,7 @@ type Querier interface {
 	Begin(context.Context) (pgx.Tx, error)
 	SendBatch(context.Context, *pgx.Batch) pgx.BatchResults
 	Exec(context.Context, string, ...any) (pgconn.CommandTag, error)
+	CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error)
 }
 
 // Client : Get transaction or new Querier.
@@ -118,6 +119,11 @@ func Client(tx Tx) Querier {
 	return lo.Ternary[Querier](!tx.empty, tx.pgxTx, pool())
 }
 
+// CopyFrom: Stream rows into a table with the COPY protocol
+func CopyFrom(ctx context.Context, tx Tx, table string, columns []string, rows pgx.CopyFromSource) (int64, error) {
+	return Client(tx).CopyFrom(ctx, pgx.Identifier{table}, columns, rows)
+}
+
 func Ping(ctx context.Context) error {
 	return pool().Ping(ctx)
 }
@@ -0,0 +1,59 @@
+package principal
+
+import (
+	"context"
+
+	pgtype "github.com/jackc/pgtype"
+	lo "github.com/samber/lo"
+)
+
+/*============================================================================*/
+/*=====*                           Principal                            *=====*/
+/*============================================================================*/
+
+// Principal: Authenticated user of a request
+type Principal struct {
+	UserID      pgtype.UUID
+	Roles       []string
+	Permissions []string
+	// Scopes of a personal access token, nil for sessions and access tokens
+	Scopes []string
+}
+
+func (obj Principal) HasRole(roles ...string) bool {
+	return lo.Some(obj.Roles, roles)
+}
+
+// Can: Whether the principal was granted a permission
+func (obj Principal) Can(permission string) bool {
+	return lo.Contains(obj.Permissions, permission)
+}
+
+// Scoped: Whether the principal is limited to scopes
+func (obj Principal) Scoped() bool {
+	return obj.Scopes != nil
+}
+
+// HasScope: Whether the principal may act within a scope
+func (obj Principal) HasScope(scope string) bool {
+	return !obj.Scoped() || lo.Contains(obj.Scopes, scope)
+}
+
+/*============================================================================*/
+/*=====*                            Context                             *=====*/
+/*============================================================================*/
+
+type ctxKey string
+
+const principalCtxKey ctxKey = "principal"
+
+// With: Attach a principal to a context
+func With(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalCtxKey, p)
+}
+
+// FromContext: Get the principal of a context
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalCtxKey).(Principal)
+	return p, ok
+}
@@ -0,0 +1,148 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	cerrors "movies/utils/cerrors"
+	logger "movies/utils/logger"
+	pg "movies/utils/pg"
+	requestid "movies/utils/requestid"
+)
+
+// ProblemContentType: Media type of error responses (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// Problem: Body of an error response
+type Problem struct {
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Status    int             `json:"status"`
+	Detail    string          `json:"detail,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Errors    json.RawMessage `json:"errors"`
+}
+
+// HandlerFunc: Handler returning its error to be rendered centrally
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Handler: Adapt a HandlerFunc, rendering any returned error
+func Handler(fn HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			Error(w, r, err)
+		}
+	}
+}
+
+// Recover: Turn panics into internal server errors
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				logger.Error(r.Context(), "Panic: %v\n%s", rec, debug.Stack())
+				write(w, r, http.StatusInternalServerError, cerrors.NewString("Internal server error"))
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// JSON: Write a JSON response
+func JSON(w http.ResponseWriter, r *http.Request, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Error(r.Context(), "Encode response failed: %v", err)
+	}
+}
+
+// NoContent: Write an empty response
+func NoContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Accepted: Write an empty response to a request processed later
+func Accepted(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// StatusError: Error rendered with a given status instead of one derived
+// from its kind
+type StatusError struct {
+	Status int
+	Err    *cerrors.Error
+}
+
+func (e *StatusError) Error() string { return e.Err.Error() }
+
+// Errors of the Unauthorized and Forbidden responses
+var (
+	ErrUnauthorized = NewStatus(http.StatusUnauthorized, cerrors.NewString("Authentication required"))
+	ErrForbidden    = NewStatus(http.StatusForbidden, cerrors.NewString("Permission denied"))
+)
+
+// NewStatus: Error to be rendered with a given status, the counterpart of
+// Status for handlers returning their error
+func NewStatus(status int, err *cerrors.Error) error {
+	return &StatusError{Status: status, Err: err}
+}
+
+// Error: Write an error response, the status depends on the kind of error
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		write(w, r, statusErr.Status, statusErr.Err)
+	} else if cerr := cerrors.IsError(err); cerr != nil {
+		if cerr.Validation() {
+			write(w, r, http.StatusUnprocessableEntity, cerr)
+		} else {
+			write(w, r, http.StatusBadRequest, cerr)
+		}
+	} else if pg.IsNotFound(err) {
+		write(w, r, http.StatusNotFound, cerrors.NewString("Not found"))
+	} else if pg.IsConstraintViolation(err) {
+		logger.Warn(r.Context(), err.Error())
+		write(w, r, http.StatusConflict, cerrors.NewString("Conflict with existing data"))
+	} else {
+		logger.Error(r.Context(), err.Error())
+		write(w, r, http.StatusInternalServerError, cerrors.NewString("Internal server error"))
+	}
+}
+
+// Status: Write an error response with a given status
+func Status(w http.ResponseWriter, r *http.Request, status int, err *cerrors.Error) {
+	write(w, r, status, err)
+}
+
+// Unauthorized: Write an authentication required response
+func Unauthorized(w http.ResponseWriter, r *http.Request) {
+	write(w, r, http.StatusUnauthorized, cerrors.NewString("Authentication required"))
+}
+
+// Forbidden: Write a permission denied response
+func Forbidden(w http.ResponseWriter, r *http.Request) {
+	write(w, r, http.StatusForbidden, cerrors.NewString("Permission denied"))
+}
+
+func write(w http.ResponseWriter, r *http.Request, status int, err *cerrors.Error) {
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    err.CLI(),
+		RequestID: requestid.FromContext(r.Context()),
+		Errors:    err.JSON(),
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		logger.Error(r.Context(), "Encode problem failed: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	logger "movies/utils/logger"
+)
+
+// Header: Header carrying the request ID in both directions
+const Header = "X-Request-ID"
+
+var valid = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+/*============================================================================*/
+/*=====*                            Context                             *=====*/
+/*============================================================================*/
+
+type ctxKey string
+
+const requestIDCtxKey ctxKey = "request_id"
+
+// With: Attach a request ID to a context
+func With(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey, id)
+}
+
+// FromContext: Get the request ID of a context, empty if none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey).(string)
+	return id
+}
+
+/*============================================================================*/
+/*=====*                           Middleware                           *=====*/
+/*============================================================================*/
+
+// Middleware: Reuse a well-formed incoming request ID or generate one,
+// then expose it in the context, the logger labels and the response
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !valid.MatchString(id) {
+			id = generate()
+		}
+
+		ctx := With(r.Context(), id)
+		ctx = logger.AddCtxLabel(ctx, "request_id", id)
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func generate() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
@@ -4,7 +4,18 @@ import (
 	"net/http"
 	"os"
 
+	authMiddleware "movies/internal/auth/middleware"
+	authRouter "movies/internal/auth/router"
+	catalogRouter "movies/internal/catalog/router"
+	oauthRouter "movies/internal/oauth/router"
+	rbacRouter "movies/internal/rbac/router"
+	revisionRouter "movies/internal/revision/router"
+	suggestionRouter "movies/internal/suggestion/router"
+	trashRouter "movies/internal/trash/router"
 	userRouter "movies/internal/user/router"
+	form "movies/utils/form"
+	render "movies/utils/render"
+	requestid "movies/utils/requestid"
 
 	mux "github.com/gorilla/mux"
 	cors "github.com/rs/cors"
@@ -16,18 +27,41 @@ func Start() error {
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost", "http://localhost:5000"},
 		AllowCredentials: true,
-		AllowedHeaders:   []string{"Authorization", "Content-Type"},
-		AllowedMethods:   []string{"GET", "POST", "DELETE", "PUT", "HEAD"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", requestid.Header},
+		ExposedHeaders:   []string{requestid.Header},
+		AllowedMethods:   []string{"GET", "POST", "DELETE", "PUT", "PATCH", "HEAD"},
 	})
 
 	// init Router
 	r := mux.NewRouter()
+	r.Use(authMiddleware.Authenticate)
+
+	authRouter := authRouter.NewAuthRouter(r)
+	authRouter.Handle()
 
 	userRouter := userRouter.NewUserRouter(r)
 	userRouter.Handle()
 
+	revisionRouter := revisionRouter.NewRevisionRouter(r)
+	revisionRouter.Handle()
+
+	suggestionRouter := suggestionRouter.NewSuggestionRouter(r)
+	suggestionRouter.Handle()
+
+	trashRouter := trashRouter.NewTrashRouter(r)
+	trashRouter.Handle()
+
+	rbacRouter := rbacRouter.NewRBACRouter(r)
+	rbacRouter.Handle()
+
+	oauthRouter := oauthRouter.NewOAuthRouter(r)
+	oauthRouter.Handle()
+
+	catalogRouter := catalogRouter.NewCatalogRouter(r)
+	catalogRouter.Handle()
+
 	port := ":" + os.Getenv("PORT")
-	handler := c.Handler(r)
+	handler := c.Handler(requestid.Middleware(form.NegotiateLocale(render.Recover(r))))
 
 	if err := http.ListenAndServe(port, handler); err != nil {
 		return err
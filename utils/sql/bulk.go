@@ -0,0 +1,146 @@
+package sql
+
+import (
+	"context"
+	"strings"
+
+	pg "movies/utils/pg"
+
+	goqu "github.com/doug-martin/goqu/v9"
+	pgtype "github.com/jackc/pgtype"
+	pgx "github.com/jackc/pgx/v4"
+	lo "github.com/samber/lo"
+)
+
+/*============================================================================*/
+/*=====*                             Upsert                             *=====*/
+/*============================================================================*/
+
+// Columns an upsert never overwrites: the key referenced by revisions and
+// suggestions, and the lifecycle ones with their own writers
+var upsertProtected = []string{
+	"id",
+	"created_at", "created_by",
+	"updated_at", "updated_by",
+	"deleted_at", "deleted_by",
+	"archived_at", "archived_by",
+}
+
+// Upsert: Insert a record, or update the row conflicting on the target columns
+//
+// Only the update columns are overwritten with the inserted values, every
+// column of the record but the target, the key and the lifecycle ones when
+// none is given. No revision is recorded, revisioned models should go through
+// Update.
+func Upsert[
+	M interface{ TableName() string },
+](ctx context.Context, tx pg.Tx, data M, record Record, target []string, update ...string) error {
+	if _, ok := any(data).(interface{ SetCreatedID(pgtype.UUID) }); ok {
+		setActor(ctx, record, "created_by")
+	}
+	if len(update) == 0 {
+		update = lo.Filter(lo.Keys(record), func(column string, _ int) bool {
+			return !lo.Contains(target, column) && !lo.Contains(upsertProtected, column)
+		})
+	} else if column, ok := lo.Find(update, func(column string) bool {
+		return lo.Contains(upsertProtected, column)
+	}); ok {
+		panic("sql: upsert can not update column `" + column + "`")
+	}
+
+	set := make(Record, len(update)+2)
+	for _, column := range update {
+		set[column] = I("excluded." + column)
+	}
+	if _, ok := any(data).(interface{ GetUpdated() Updated }); ok {
+		set["updated_at"] = NOW
+		setActor(ctx, set, "updated_by")
+	}
+
+	sql, args, err := pg.SQLBuilder().
+		Insert(data.TableName()).
+		Rows(record).
+		OnConflict(goqu.DoUpdate(strings.Join(target, ", "), set)).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return err
+	}
+	return mapConstraint(data, pg.Get(ctx, tx, data, sql, args...), record)
+}
+
+/*============================================================================*/
+/*=====*                              Bulk                              *=====*/
+/*============================================================================*/
+
+// CreateMany: Insert records in a single statement and return the created rows,
+// in order. Records must share the same columns.
+func CreateMany[M Table](ctx context.Context, tx pg.Tx, records []Record) ([]*M, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	model := *new(M)
+	rows := make([]any, len(records))
+	for i, record := range records {
+		if _, ok := any(&model).(interface{ SetCreatedID(pgtype.UUID) }); ok {
+			setActor(ctx, record, "created_by")
+		}
+		rows[i] = record
+	}
+
+	sql, args, err := pg.SQLBuilder().
+		Insert(model.TableName()).
+		Rows(rows...).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return nil, err
+	}
+	var items []*M
+	if err := pg.Select(ctx, tx, &items, sql, args...); err != nil {
+		return nil, mapConstraint(model, err, nil)
+	}
+	return items, nil
+}
+
+// CopyFrom: Stream rows into the table of a model with COPY, for imports too
+// large for an INSERT. Rows skip conforming and revisions, and actor columns
+// are not filled.
+func CopyFrom[M Table](ctx context.Context, tx pg.Tx, columns []string, rows pgx.CopyFromSource) (int64, error) {
+	model := *new(M)
+	count, err := pg.CopyFrom(ctx, tx, model.TableName(), columns, rows)
+	return count, mapConstraint(model, err, nil)
+}
+
+// CopySlice: Rows of a slice, each mapped to the values of the copied columns
+func CopySlice[T any](items []T, values func(T) []any) pgx.CopyFromSource {
+	return pgx.CopyFromSlice(len(items), func(i int) ([]any, error) {
+		return values(items[i]), nil
+	})
+}
+
+// CopyIterator: Rows pulled one at a time until next reports no more rows, so
+// a source (file, cursor, channel) is never loaded in memory
+func CopyIterator(next func() ([]any, bool, error)) pgx.CopyFromSource {
+	return &copyIterator{next: next}
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+type copyIterator struct {
+	next   func() ([]any, bool, error)
+	values []any
+	err    error
+}
+
+func (it *copyIterator) Next() bool {
+	var ok bool
+	it.values, ok, it.err = it.next()
+	return ok && it.err == nil
+}
+
+func (it *copyIterator) Values() ([]any, error) { return it.values, it.err }
+
+func (it *copyIterator) Err() error { return it.err }
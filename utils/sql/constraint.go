@@ -0,0 +1,101 @@
+package sql
+
+import (
+	"fmt"
+
+	cerrors "movies/utils/cerrors"
+	pg "movies/utils/pg"
+
+	exp "github.com/doug-martin/goqu/v9/exp"
+	pgerrcode "github.com/jackc/pgerrcode"
+)
+
+/*============================================================================*/
+/*=====*                      Constraint Registry                       *=====*/
+/*============================================================================*/
+
+// Constraint: Validation error reported when a constraint is violated
+type Constraint struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// ConstraintRegistry: Known constraints of a model, by constraint name
+type ConstraintRegistry struct {
+	constraints map[string]Constraint
+}
+
+// Constrained: Model mapping its constraint violations to validation errors
+type Constrained interface {
+	Constraints() *ConstraintRegistry
+}
+
+func NewConstraintRegistry() *ConstraintRegistry {
+	return &ConstraintRegistry{constraints: map[string]Constraint{}}
+}
+
+// Register: Declare the JSON field, validation code and message of a constraint
+//
+// An empty code or message is derived from the kind of violation.
+func (r *ConstraintRegistry) Register(name, field, code, message string) *ConstraintRegistry {
+	if _, ok := r.constraints[name]; ok {
+		panic(fmt.Sprintf("constraint `%s` already registered", name))
+	}
+	r.constraints[name] = Constraint{Field: field, Code: code, Message: message}
+	return r
+}
+
+// Map: Turn a registered unique, foreign key, check or exclusion violation into
+// a validation error, any other error is returned as is
+func (r *ConstraintRegistry) Map(err error, record Record) error {
+	pgErr := pg.HavePGErr(err)
+	if pgErr == nil {
+		return err
+	}
+	constraint, ok := r.constraints[pgErr.ConstraintName]
+	if !ok {
+		return err
+	}
+
+	var code, message string
+	switch pgErr.Code {
+	case pgerrcode.UniqueViolation:
+		code, message = "unique", "`%s` is already taken"
+	case pgerrcode.ForeignKeyViolation:
+		code, message = "exists", "`%s` references a missing row"
+	case pgerrcode.CheckViolation:
+		code, message = "check", "`%s` is invalid"
+	case pgerrcode.ExclusionViolation:
+		code, message = "exclusion", "`%s` conflicts with an existing row"
+	default:
+		return err
+	}
+	if constraint.Code != "" {
+		code = constraint.Code
+	}
+	if constraint.Message != "" {
+		message = constraint.Message
+	} else {
+		message = fmt.Sprintf(message, constraint.Field)
+	}
+
+	var value any
+	if v, ok := record[constraint.Field]; ok {
+		if _, isExp := v.(exp.Expression); !isExp {
+			value = v
+		}
+	}
+	return cerrors.NewValidation(code, constraint.Field, message, value)
+}
+
+// mapConstraint: Map the error through the registry of the model, if any
+func mapConstraint(model any, err error, record Record) error {
+	if err == nil {
+		return nil
+	}
+	if c, ok := model.(Constrained); ok {
+		return c.Constraints().Map(err, record)
+	}
+	return err
+}
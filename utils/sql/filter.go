@@ -0,0 +1,253 @@
+package sql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	cerrors "movies/utils/cerrors"
+	form "movies/utils/form"
+
+	exp "github.com/doug-martin/goqu/v9/exp"
+	lo "github.com/samber/lo"
+)
+
+/*============================================================================*/
+/*=====*                            Listing                             *=====*/
+/*============================================================================*/
+
+// Filter operators of a listing
+const (
+	OpEq       = "eq"
+	OpNeq      = "neq"
+	OpGt       = "gt"
+	OpGte      = "gte"
+	OpLt       = "lt"
+	OpLte      = "lte"
+	OpIn       = "in"
+	OpContains = "contains"
+	OpNull     = "null"
+)
+
+// ListSpec: Columns of a model that list endpoints may filter, sort and select
+type ListSpec struct {
+	filters map[string][]string
+	sorts   []string
+	fields  []string
+}
+
+// Listable: Model declaring what its list endpoints accept
+type Listable interface {
+	Table
+	Listing() *ListSpec
+}
+
+// List: Parsed filters, sort and fields of a list request
+type List struct {
+	Where  []exp.Expression
+	Sort   []Sort
+	Fields []string
+}
+
+func NewListSpec() *ListSpec {
+	return &ListSpec{filters: map[string][]string{}}
+}
+
+// Filter: Allow a column to be filtered with some operators
+func (s *ListSpec) Filter(column string, operators ...string) *ListSpec {
+	s.filters[column] = append(s.filters[column], operators...)
+	return s
+}
+
+// Sort: Allow columns to be sorted on, they must be NOT NULL to paginate
+func (s *ListSpec) Sort(columns ...string) *ListSpec {
+	s.sorts = append(s.sorts, columns...)
+	return s
+}
+
+// Field: Allow columns to be selected
+func (s *ListSpec) Field(columns ...string) *ListSpec {
+	s.fields = append(s.fields, columns...)
+	return s
+}
+
+var filterKey = regexp.MustCompile(`^filter\[([a-z0-9_]+)\](?:\[([a-z]+)\])?$`)
+
+// ParseList: Parse `filter[<column>][<op>]=<value>`, `sort=-<column>,<column>`
+// and `fields=<column>,<column>` from a query, against the spec of the model
+//
+// A filter without operator is an equality, `in` takes a comma separated list,
+// `null` takes true or false. Values are parsed as the type of the model field,
+// so a malformed one is a validation error instead of a database one. Other
+// query keys are left to the caller.
+func ParseList[M Listable](query url.Values) (List, error) {
+	spec := (*new(M)).Listing()
+	row := reflect.ValueOf(new(M)).Elem()
+
+	var list List
+	var er *cerrors.Error
+	keys := lo.Keys(query)
+	sort.Strings(keys)
+	for _, key := range keys {
+		match := filterKey.FindStringSubmatch(key)
+		if match == nil {
+			if strings.HasPrefix(key, "filter") {
+				er = er.Append(cerrors.NewValidation("filter", key, fmt.Sprintf("`%s` is not a valid filter", key), key))
+			}
+			continue
+		}
+		column, op := match[1], lo.Ternary(match[2] == "", OpEq, match[2])
+		operators, ok := spec.filters[column]
+		if !ok {
+			er = er.Append(cerrors.NewValidation("filter", key, fmt.Sprintf("`%s` cannot be filtered", column), column))
+			continue
+		} else if !lo.Contains(operators, op) {
+			er = er.Append(cerrors.NewValidation("oneof", key, fmt.Sprintf("`%s` accepts %s", column, strings.Join(operators, ", ")), op))
+			continue
+		}
+		field, ok := fieldByColumn(row, column)
+		if !ok {
+			panic("sql: unknown filter column `" + column + "`")
+		}
+		for _, value := range query[key] {
+			expression, err := filterExpression(I(column), field.Type(), op, value)
+			if err != nil {
+				er = er.Append(cerrors.NewValidation("type", key, fmt.Sprintf("`%s` is not a valid value of `%s`", value, column), value))
+				continue
+			}
+			list.Where = append(list.Where, expression)
+		}
+	}
+
+	for _, column := range splitList(query.Get("sort")) {
+		desc := strings.HasPrefix(column, "-")
+		column = strings.TrimPrefix(column, "-")
+		if !lo.Contains(spec.sorts, column) {
+			er = er.Append(cerrors.NewValidation("oneof", "sort", fmt.Sprintf("`%s` cannot be sorted on", column), column))
+			continue
+		}
+		list.Sort = append(list.Sort, Sort{Column: column, Desc: desc})
+	}
+
+	for _, column := range splitList(query.Get("fields")) {
+		if !lo.Contains(spec.fields, column) {
+			er = er.Append(cerrors.NewValidation("oneof", "fields", fmt.Sprintf("`%s` cannot be selected", column), column))
+			continue
+		}
+		list.Fields = append(list.Fields, column)
+	}
+
+	if er != nil {
+		return list, er
+	}
+	return list, nil
+}
+
+// List: Apply the filters of a list request, and its fields plus the sort
+// columns and id needed by pagination
+func (d *readQuery[M]) List(list List) *readQuery[M] {
+	d.append(d.dataset.Where(list.Where...))
+	if len(list.Fields) > 0 {
+		columns := append([]string{"id"}, list.Fields...)
+		for _, s := range list.Sort {
+			columns = append(columns, s.Column)
+		}
+		selects := lo.Map(lo.Uniq(columns), func(column string, _ int) any { return I(column) })
+		d.append(d.dataset.Select(selects...))
+	}
+	return d
+}
+
+// Project: Page with its items reduced to the requested fields, as is when
+// none were requested
+func (page Page[M]) Project(fields []string) (any, error) {
+	if len(fields) == 0 {
+		return page, nil
+	}
+	data, err := json.Marshal(page.Items)
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		for key := range item {
+			if !lo.Contains(fields, key) {
+				delete(item, key)
+			}
+		}
+	}
+	return Page[map[string]json.RawMessage]{
+		Items:      lo.ToSlicePtr(items),
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}, nil
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+// Characters with a meaning in LIKE patterns
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// filterExpression: Condition of a filter, its value parsed as the field type
+func filterExpression(col exp.IdentifierExpression, typ reflect.Type, op, value string) (exp.Expression, error) {
+	switch op {
+	case OpIn:
+		values := splitList(value)
+		if len(values) == 0 {
+			return nil, fmt.Errorf("empty list")
+		}
+		parsed := make([]any, len(values))
+		for i, v := range values {
+			p, err := form.ParseValue(typ, v)
+			if err != nil {
+				return nil, err
+			}
+			parsed[i] = p
+		}
+		return col.In(parsed), nil
+	case OpContains:
+		return col.ILike("%" + likeEscaper.Replace(value) + "%"), nil
+	case OpNull:
+		null, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return lo.Ternary(null, col.IsNull(), col.IsNotNull()), nil
+	}
+
+	parsed, err := form.ParseValue(typ, value)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case OpEq:
+		return col.Eq(parsed), nil
+	case OpNeq:
+		return col.Neq(parsed), nil
+	case OpGt:
+		return col.Gt(parsed), nil
+	case OpGte:
+		return col.Gte(parsed), nil
+	case OpLt:
+		return col.Lt(parsed), nil
+	case OpLte:
+		return col.Lte(parsed), nil
+	}
+	return nil, fmt.Errorf("unknown operator `%s`", op)
+}
+
+func splitList(value string) []string {
+	return lo.Compact(lo.Map(strings.Split(value, ","), func(v string, _ int) string {
+		return strings.TrimSpace(v)
+	}))
+}
@@ -2,8 +2,11 @@ package sql
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"reflect"
 
+	cerrors "movies/utils/cerrors"
 	form "movies/utils/form"
 	pg "movies/utils/pg"
 
@@ -196,30 +199,54 @@ func (obj Setting) GetSetting(key string, empty any) (any, error) {
 
 func (obj Setting) GetSettingBool(key string, empty bool) (bool, error) {
 	value, err := obj.GetSetting(key, empty)
-	return value.(bool), err
+	if err != nil {
+		return empty, err
+	} else if v, ok := value.(bool); ok {
+		return v, nil
+	}
+	return empty, settingTypeError(key, "boolean", value)
 }
 
 func (obj Setting) GetSettingInt(key string, empty int) (int, error) {
 	value, err := obj.GetSetting(key, empty)
-	if v, ok := value.(int); ok {
+	if err != nil {
+		return empty, err
+	} else if v, ok := value.(int); ok {
 		return v, nil
-	} else if v, ok := value.(float64); ok {
-		return int(v), nil
-	} else if v, ok := value.(float32); ok {
+	} else if v, ok := value.(float64); ok && v == math.Trunc(v) {
 		return int(v), nil
 	}
-	return 0, err
+	return empty, settingTypeError(key, "integer", value)
 }
 
 func (obj Setting) GetSettingFloat(key string, empty float64) (float64, error) {
 	value, err := obj.GetSetting(key, empty)
-	return value.(float64), err
+	if err != nil {
+		return empty, err
+	} else if v, ok := value.(float64); ok {
+		return v, nil
+	} else if v, ok := value.(int); ok {
+		return float64(v), nil
+	}
+	return empty, settingTypeError(key, "number", value)
 }
 
 func (obj Setting) GetSettingUUID(key string, empty pgtype.UUID) (pgtype.UUID, error) {
 	value, err := obj.GetSetting(key, empty)
-	if err == nil {
-		return pg.ParseUUID(value.(string))
+	if err != nil {
+		return empty, err
 	}
-	return value.(pgtype.UUID), err
+	switch v := value.(type) {
+	case pgtype.UUID:
+		return v, nil
+	case string:
+		if id, err := pg.ParseUUID(v); err == nil {
+			return id, nil
+		}
+	}
+	return empty, settingTypeError(key, "UUID", value)
+}
+
+func settingTypeError(key, kind string, value any) error {
+	return cerrors.NewValidation("type", key, fmt.Sprintf("`%s` must be a %s", key, kind), value)
 }
@@ -0,0 +1,248 @@
+package sql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	cerrors "movies/utils/cerrors"
+	pg "movies/utils/pg"
+
+	goqu "github.com/doug-martin/goqu/v9"
+	exp "github.com/doug-martin/goqu/v9/exp"
+	pgtype "github.com/jackc/pgtype"
+	lo "github.com/samber/lo"
+)
+
+/*============================================================================*/
+/*=====*                           Pagination                           *=====*/
+/*============================================================================*/
+
+// DefaultPageSize: Size of a page when none is asked
+const DefaultPageSize = 20
+
+// Page: Items of a keyset pagination, with the cursors of its neighbours
+type Page[M any] struct {
+	Items      []*M    `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+}
+
+// Sort: Column of a pagination ordering
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Asc: Ascending pagination ordering
+func Asc(column string) Sort { return Sort{Column: column} }
+
+// Desc: Descending pagination ordering
+func Desc(column string) Sort { return Sort{Column: column, Desc: true} }
+
+// Paginate: Fetch the page following (or preceding) a cursor, the first page
+// when the cursor is empty
+//
+// Rows are ordered by the given columns then by id, and pages are bounded by
+// keyset predicates on those values instead of offsets, so rows inserted
+// concurrently never shift or repeat a page. Sort columns must be NOT NULL.
+func (d readQuery[M]) Paginate(ctx context.Context, tx pg.Tx, cursor string, size uint, order ...Sort) (Page[M], error) {
+	page := Page[M]{Items: []*M{}}
+	if d.empty {
+		return page, nil
+	}
+	if size == 0 {
+		size = DefaultPageSize
+	}
+	if !lo.ContainsBy(order, func(s Sort) bool { return s.Column == "id" }) {
+		order = append(order, Sort{Column: "id", Desc: len(order) > 0 && order[len(order)-1].Desc})
+	}
+
+	dataset := d.query(ctx)
+	backward := false
+	if cursor != "" {
+		key, err := decodeCursor[M](cursor, order)
+		if err != nil {
+			return page, err
+		}
+		backward = key.backward
+		dataset = dataset.Where(keyset(d.table, order, key.values, backward))
+	}
+
+	ordering := make([]exp.OrderedExpression, len(order))
+	for i, s := range order {
+		col := T(d.table).Col(s.Column)
+		ordering[i] = lo.Ternary(s.Desc != backward, col.Desc(), col.Asc())
+	}
+
+	sql, args, err := dataset.Order(ordering...).Limit(size + 1).ToSQL()
+	if err != nil {
+		return page, err
+	}
+	if err := pg.Select(ctx, tx, &page.Items, sql, args...); err != nil {
+		return page, err
+	}
+
+	more := uint(len(page.Items)) > size
+	if more {
+		page.Items = page.Items[:size]
+	}
+	if backward {
+		page.Items = lo.Reverse(page.Items)
+	}
+	if len(page.Items) == 0 {
+		return page, nil
+	}
+
+	// Going forward, a next page exists if a row was left over, and a previous
+	// one if a cursor was followed. Backward, the other way around.
+	first, last := page.Items[0], page.Items[len(page.Items)-1]
+	if more || backward {
+		next, err := encodeCursor(last, order, false)
+		if err != nil {
+			return page, err
+		}
+		page.NextCursor = &next
+	}
+	if (backward && more) || (!backward && cursor != "") {
+		prev, err := encodeCursor(first, order, true)
+		if err != nil {
+			return page, err
+		}
+		page.PrevCursor = &prev
+	}
+	return page, nil
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+type cursorKey struct {
+	backward bool
+	values   []any
+}
+
+// cursorData: Sort values of a cursor, with the ordering they were taken in
+type cursorData[V any] struct {
+	Order  string `json:"o"`
+	Values []V    `json:"v"`
+}
+
+// orderSignature: Columns of an ordering, `-` prefixed when descending
+func orderSignature(order []Sort) string {
+	columns := make([]string, len(order))
+	for i, s := range order {
+		columns[i] = lo.Ternary(s.Desc, "-", "") + s.Column
+	}
+	return strings.Join(columns, ",")
+}
+
+// keyset: Rows strictly after the key in the ordering, before it if backward
+//
+// (a, b) > (x, y) is expanded to a > x OR (a = x AND b > y), which also
+// handles orderings mixing directions.
+func keyset(table string, order []Sort, values []any, backward bool) exp.Expression {
+	alternatives := make([]exp.Expression, len(order))
+	for i, s := range order {
+		terms := make([]exp.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, T(table).Col(order[j].Column).Eq(values[j]))
+		}
+		col := T(table).Col(s.Column)
+		terms = append(terms, lo.Ternary(s.Desc != backward, col.Lt(values[i]), col.Gt(values[i])))
+		alternatives[i] = goqu.And(terms...)
+	}
+	return goqu.Or(alternatives...)
+}
+
+// encodeCursor: `<direction>.<short id>.<sort values>`, the id is kept short and
+// the sort values as base64 JSON along with their ordering
+func encodeCursor[M any](item *M, order []Sort, backward bool) (string, error) {
+	row := reflect.ValueOf(item).Elem()
+	values := make([]any, 0, len(order))
+	var id pgtype.UUID
+	for _, s := range order {
+		field, ok := fieldByColumn(row, s.Column)
+		if !ok {
+			panic("sql: unknown pagination column `" + s.Column + "`")
+		}
+		if s.Column == "id" {
+			id = field.Interface().(pgtype.UUID)
+			continue
+		}
+		values = append(values, field.Interface())
+	}
+	data, err := json.Marshal(cursorData[any]{Order: orderSignature(order), Values: values})
+	if err != nil {
+		return "", err
+	}
+	return lo.Ternary(backward, "p", "n") + "." + pg.EncodeShortUUID(id) + "." + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor: Sort values of a cursor, typed like the model fields
+//
+// A cursor only follows the ordering it was issued for.
+func decodeCursor[M any](cursor string, order []Sort) (cursorKey, error) {
+	invalid := cerrors.NewValidation("cursor", "cursor", "`cursor` is invalid", cursor)
+
+	parts := strings.Split(cursor, ".")
+	if len(parts) != 3 || (parts[0] != "n" && parts[0] != "p") {
+		return cursorKey{}, invalid
+	}
+	id, err := pg.DecodeShortUUID(parts[1])
+	if err != nil {
+		return cursorKey{}, invalid
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return cursorKey{}, invalid
+	}
+	var payload cursorData[json.RawMessage]
+	if err := json.Unmarshal(data, &payload); err != nil || len(payload.Values) != len(order)-1 {
+		return cursorKey{}, invalid
+	} else if payload.Order != orderSignature(order) {
+		return cursorKey{}, cerrors.NewValidation("cursor", "cursor", "`cursor` does not match the requested sort", cursor)
+	}
+	raws := payload.Values
+
+	row := reflect.ValueOf(new(M)).Elem()
+	key := cursorKey{backward: parts[0] == "p", values: make([]any, len(order))}
+	for i, s := range order {
+		if s.Column == "id" {
+			key.values[i] = id
+			continue
+		}
+		field, ok := fieldByColumn(row, s.Column)
+		if !ok {
+			panic("sql: unknown pagination column `" + s.Column + "`")
+		}
+		value := reflect.New(field.Type())
+		if err := json.Unmarshal(raws[0], value.Interface()); err != nil {
+			return cursorKey{}, invalid
+		}
+		raws = raws[1:]
+		key.values[i] = value.Elem().Interface()
+	}
+	return key, nil
+}
+
+// fieldByColumn: Field of a struct mapped to a column, embedded structs included
+func fieldByColumn(row reflect.Value, column string) (reflect.Value, bool) {
+	typ := row.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if field, ok := fieldByColumn(row.Field(i), column); ok {
+				return field, true
+			}
+			continue
+		}
+		if f.Tag.Get("db") == column {
+			return row.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
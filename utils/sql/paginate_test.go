@@ -0,0 +1,43 @@
+package sql
+
+import (
+	"testing"
+
+	cerrors "movies/utils/cerrors"
+	pg "movies/utils/pg"
+
+	pgtype "github.com/jackc/pgtype"
+)
+
+type cursorModel struct {
+	ID            pgtype.UUID `db:"id"`
+	Title         string      `db:"title"`
+	OriginalTitle string      `db:"original_title"`
+}
+
+func TestCursorOrder(t *testing.T) {
+	item := &cursorModel{ID: pg.NewUUID(), Title: "Alien", OriginalTitle: "Alien"}
+	order := []Sort{Asc("title"), Asc("id")}
+	cursor, err := encodeCursor(item, order, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := decodeCursor[cursorModel](cursor, order)
+	if err != nil {
+		t.Fatal(err)
+	} else if key.backward || key.values[0] != "Alien" || key.values[1] != item.ID {
+		t.Errorf("unexpected key %+v", key)
+	}
+
+	for _, other := range [][]Sort{
+		{Asc("original_title"), Asc("id")},
+		{Desc("title"), Desc("id")},
+		{Asc("title"), Desc("id")},
+	} {
+		_, err := decodeCursor[cursorModel](cursor, other)
+		if cerr := cerrors.IsError(err); cerr == nil || !cerr.Validation() {
+			t.Errorf("%s: expected a cursor validation error, got %v", orderSignature(other), err)
+		}
+	}
+}
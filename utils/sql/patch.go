@@ -0,0 +1,172 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	cerrors "movies/utils/cerrors"
+	form "movies/utils/form"
+
+	lo "github.com/samber/lo"
+)
+
+/*============================================================================*/
+/*=====*                          Merge Patch                           *=====*/
+/*============================================================================*/
+
+// MergePatchContentType: Media type of RFC 7396 bodies
+const MergePatchContentType = "application/merge-patch+json"
+
+type patchField struct {
+	name     string
+	column   string
+	typ      reflect.Type
+	validate string
+	mod      string
+	// Column of the diacritic-free shadow of the field, if any
+	shadow string
+}
+
+// DecodeMergePatch: Decode an RFC 7396 merge-patch body into a record of the
+// columns to update
+//
+// Only the present fields are decoded, conformed and validated against the
+// struct tags of the model, a null value sets the column to NULL. Shadow columns
+// of patched fields are filled too. Fields missing from allowed
+// or from the model are reported as validation errors.
+func DecodeMergePatch(ctx context.Context, r io.Reader, model Table, allowed []string) (Record, error) {
+	values := make(map[string]json.RawMessage)
+	if err := form.DecodeJSON(r, &values); err != nil {
+		return nil, err
+	} else if len(values) == 0 {
+		return nil, cerrors.NewString("JSON Patch is empty")
+	}
+	return PatchRecord(ctx, model, allowed, values)
+}
+
+// PatchRecord: Record of the columns to update from raw JSON values by field
+// name, decoded, conformed and validated as a merge-patch body would be
+func PatchRecord(ctx context.Context, model Table, allowed []string, values map[string]json.RawMessage) (Record, error) {
+	fields := patchFields(reflect.Indirect(reflect.ValueOf(model)).Type())
+	names := lo.Keys(values)
+	sort.Strings(names)
+
+	var er *cerrors.Error
+	var err error
+	record := make(Record, len(values))
+	for _, name := range names {
+		field, ok := fields[name]
+		if !ok || !lo.Contains(allowed, name) {
+			er = er.Append(cerrors.NewValidation("unknown", name, fmt.Sprintf("`%s` cannot be patched", name), name))
+			continue
+		}
+
+		value, cerr := field.decode(name, values[name])
+		if cerr != nil {
+			er = er.Append(cerr)
+			continue
+		}
+		if field.mod != "" {
+			if value, err = conformValue(value, field.mod); err != nil {
+				return nil, err
+			}
+		}
+		if field.validate != "" {
+			if err := form.ValidateVarContext(ctx, name, value, field.validate); err != nil {
+				cerr := cerrors.IsError(err)
+				if cerr == nil {
+					return nil, err
+				}
+				er = er.Append(cerr)
+				continue
+			}
+		}
+		record[field.column] = value
+		if field.shadow != "" {
+			record[field.shadow] = form.Unaccent(value)
+		}
+	}
+	if er != nil {
+		return nil, er
+	}
+	return record, nil
+}
+
+// decode: Decode a raw value, null is refused by types that cannot hold it
+func (f patchField) decode(name string, raw json.RawMessage) (any, *cerrors.Error) {
+	ptr := reflect.New(f.typ)
+	if string(raw) == "null" && !nullable(f.typ) {
+		return nil, cerrors.NewValidation("nonnull", name, fmt.Sprintf("`%s` cannot be null", name), nil)
+	}
+	if err := json.Unmarshal(raw, ptr.Interface()); err != nil {
+		return nil, cerrors.NewValidation("type", name, fmt.Sprintf("`%s` has an invalid type", name), string(raw))
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+func nullable(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return reflect.PointerTo(typ).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem())
+}
+
+// conformValue: Apply `mod:` tags to a copy of a value
+func conformValue(value any, tags string) (any, error) {
+	ptr := reflect.New(reflect.TypeOf(value))
+	ptr.Elem().Set(reflect.ValueOf(value))
+	if err := form.ConformVar(ptr.Interface(), tags); err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// patchFields: Columns of a model by JSON name, embedded structs included
+func patchFields(typ reflect.Type) map[string]patchField {
+	byGoName := make(map[string]patchField)
+	shadows := make(map[string]string)
+	collectFields(typ, byGoName, shadows)
+
+	fields := make(map[string]patchField, len(byGoName))
+	for goName, field := range byGoName {
+		field.shadow = shadows[goName]
+		fields[field.name] = field
+	}
+	return fields
+}
+
+func collectFields(typ reflect.Type, fields map[string]patchField, shadows map[string]string) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			collectFields(f.Type, fields, shadows)
+			continue
+		}
+		column := f.Tag.Get("db")
+		if !f.IsExported() || column == "" || column == "-" {
+			continue
+		}
+		mod := f.Tag.Get("mod")
+		if source, ok := form.UnaccentSource(mod); ok {
+			shadows[source] = column
+			continue
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[f.Name] = patchField{
+			name:     name,
+			column:   column,
+			typ:      f.Type,
+			validate: f.Tag.Get("validate"),
+			mod:      mod,
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	cerrors "movies/utils/cerrors"
+	pg "movies/utils/pg"
+	principal "movies/utils/principal"
+
+	goqu "github.com/doug-martin/goqu/v9"
+	exp "github.com/doug-martin/goqu/v9/exp"
+	pgtype "github.com/jackc/pgtype"
+	lo "github.com/samber/lo"
+)
+
+/*============================================================================*/
+/*=====*                           Revisioned                           *=====*/
+/*============================================================================*/
+
+// Revisioned: Mixin recording a revision for every update of the model
+type Revisioned struct{}
+
+func (Revisioned) revisioned() {}
+
+// Revisionable: Model embedding the Revisioned mixin
+type Revisionable interface {
+	GetPK() pgtype.UUID
+	TableName() string
+	revisioned()
+}
+
+// Columns never recorded in a revision diff
+var revisionIgnored = []string{"updated_at", "updated_by"}
+
+// Columns holding the author of a revision, by priority
+var revisionActors = []string{"updated_by", "deleted_by", "archived_by"}
+
+/*============================================================================*/
+/*=====*                            Revision                            *=====*/
+/*============================================================================*/
+
+type Revision struct {
+	ID          pgtype.UUID        `json:"id" db:"id"`
+	EntityTable string             `json:"entity_table" db:"entity_table"`
+	EntityID    pgtype.UUID        `json:"entity_id" db:"entity_id"`
+	Version     int                `json:"version" db:"version"`
+	Diff        pgtype.JSONB       `json:"diff" db:"diff"`
+	UpdatedBy   pgtype.UUID        `json:"updated_by" db:"updated_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at" db:"created_at"`
+}
+
+func (Revision) TableName() string { return "revisions" }
+
+func (obj Revision) GetDiff() (Diff, error) {
+	diff := Diff{}
+	return diff, json.Unmarshal(obj.Diff.Bytes, &diff)
+}
+
+// Change: Value of a column before and after a revision
+type Change struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Diff: Changes of a revision, by column
+type Diff map[string]Change
+
+/*============================================================================*/
+/*=====*                              API                               *=====*/
+/*============================================================================*/
+
+// Revisions: List the revisions of an entity, oldest first
+func Revisions(ctx context.Context, tx pg.Tx, table string, id pgtype.UUID) ([]*Revision, error) {
+	return Read[Revision]().
+		Where(I("entity_table").Eq(table), I("entity_id").Eq(id)).
+		Order(I("version").Asc()).
+		FindAll(ctx, tx)
+}
+
+// DiffRevisions: Combined changes of an entity between two versions
+func DiffRevisions(ctx context.Context, tx pg.Tx, table string, id pgtype.UUID, from, to int) (Diff, error) {
+	if from < 0 || to <= from {
+		return nil, cerrors.NewValidation("gtfield", "to", "`to` must be greater than `from`", to)
+	}
+
+	revisions, err := Read[Revision]().
+		Where(
+			I("entity_table").Eq(table),
+			I("entity_id").Eq(id),
+			I("version").Gt(from),
+			I("version").Lte(to),
+		).
+		Order(I("version").Asc()).
+		FindAll(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return composeRevisions(revisions)
+}
+
+// RevertByPK: Restore the allowed columns of an entity to the state they had
+// at a version
+//
+// Old values are decoded and validated like a merge-patch of those columns,
+// bookkeeping and shadow columns are never restored as is. The revert goes
+// through UpdateByPK, so it is recorded as a new revision.
+func RevertByPK[M Revisionable](ctx context.Context, tx pg.Tx, data M, version int, allowed []string, record Record) error {
+	revisions, err := Read[Revision]().
+		Where(
+			I("entity_table").Eq(data.TableName()),
+			I("entity_id").Eq(data.GetPK()),
+			I("version").Gt(version),
+		).
+		Order(I("version").Asc()).
+		FindAll(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	diff, err := composeRevisions(revisions)
+	if err != nil {
+		return err
+	} else if len(diff) == 0 {
+		return cerrors.NewValidation("version", "version", "Entity is already at this version", version)
+	}
+
+	state := make(map[string]json.RawMessage, len(diff))
+	for column, change := range diff {
+		if !lo.Contains(allowed, column) {
+			continue
+		}
+		raw, err := json.Marshal(change.Old)
+		if err != nil {
+			return err
+		}
+		state[column] = raw
+	}
+	if len(state) == 0 {
+		return cerrors.NewValidation("version", "version", "Entity is already at this version", version)
+	}
+	values, err := PatchRecord(ctx, data, allowed, state)
+	if err != nil {
+		return err
+	}
+	for column, value := range values {
+		record[column] = value
+	}
+	return UpdateByPK(ctx, tx, data, true, record)
+}
+
+// SnapshotByPK: Get the current row of a model as a JSON object
+func SnapshotByPK[M Revisionable](ctx context.Context, tx pg.Tx, data M) (map[string]any, error) {
+	return snapshotRow(ctx, tx, data.TableName(), goqu.I("id").Eq(data.GetPK()), false)
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+// updateRevisioned: Update a single row and record the revision
+func updateRevisioned[M Revisionable](ctx context.Context, tx pg.Tx, data M, record Record, expressions exp.Expression) error {
+	// Ensure to be inside a transaction
+	tx, err := pg.EnsureTx(ctx, tx)
+	defer tx.RollbackDefer(ctx)
+	if err != nil {
+		return err
+	}
+
+	before, err := snapshotRow(ctx, tx, data.TableName(), expressions, true)
+	if err != nil {
+		return err
+	}
+	if err := updateRow(ctx, tx, data, record, expressions); err != nil {
+		return err
+	}
+	after, err := snapshotRow(ctx, tx, data.TableName(), goqu.I("id").Eq(data.GetPK()), false)
+	if err != nil {
+		return err
+	}
+
+	diff := Diff{}
+	for column, value := range after {
+		if lo.Contains(revisionIgnored, column) || reflect.DeepEqual(before[column], value) {
+			continue
+		}
+		diff[column] = Change{Old: before[column], New: value}
+	}
+	if len(diff) == 0 {
+		return tx.Commit(ctx)
+	}
+
+	jsonDiff, err := pg.NewJSONBFromAny(diff)
+	if err != nil {
+		return err
+	}
+	actor := revisionActor(ctx, record)
+
+	// The row lock taken by the snapshot serializes versions of an entity
+	version := Read[Revision]().
+		Select(COALESCE(MAX("version"), 0).As("version")).
+		Where(I("entity_table").Eq(data.TableName()), I("entity_id").Eq(data.GetPK())).
+		Raw()
+	sql, args, err := pg.SQLBuilder().
+		Insert(Revision{}.TableName()).
+		Rows(Record{
+			"entity_table": data.TableName(),
+			"entity_id":    data.GetPK(),
+			"version":      L("(?) + 1", version),
+			"diff":         jsonDiff,
+			"updated_by":   actor,
+		}).
+		ToSQL()
+	if err != nil {
+		return err
+	}
+	if _, err := pg.Client(tx).Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// revisionActor: Author of a revision, from the actor columns of the record or
+// else from the principal of the context
+func revisionActor(ctx context.Context, record Record) pgtype.UUID {
+	for _, column := range revisionActors {
+		if actor, ok := record[column].(pgtype.UUID); ok && actor.Status == pgtype.Present {
+			return actor
+		}
+	}
+	if p, ok := principal.FromContext(ctx); ok && p.UserID.Status == pgtype.Present {
+		return p.UserID
+	}
+	return pg.NullUUID()
+}
+
+// snapshotRow: Get a row as a JSON object
+func snapshotRow(ctx context.Context, tx pg.Tx, table string, expressions exp.Expression, lock bool) (map[string]any, error) {
+	query := pg.SQLBuilder().
+		From(T(table).As("t")).
+		Select(L("to_jsonb(t)")).
+		Where(expressions)
+	if lock {
+		query = query.ForUpdate(exp.Wait)
+	}
+
+	sql, args, err := query.ToSQL()
+	if err != nil {
+		return nil, err
+	}
+	var row pgtype.JSONB
+	if err := pg.Get(ctx, tx, &row, sql, args...); err != nil {
+		return nil, err
+	}
+	data := make(map[string]any)
+	return data, json.Unmarshal(row.Bytes, &data)
+}
+
+// composeRevisions: Merge consecutive revisions into a single diff
+func composeRevisions(revisions []*Revision) (Diff, error) {
+	result := Diff{}
+	for _, revision := range revisions {
+		diff, err := revision.GetDiff()
+		if err != nil {
+			return nil, err
+		}
+		for column, change := range diff {
+			if previous, ok := result[column]; ok {
+				change.Old = previous.Old
+			}
+			result[column] = change
+		}
+	}
+
+	// Drop columns restored to their original value
+	for column, change := range result {
+		if reflect.DeepEqual(change.Old, change.New) {
+			delete(result, column)
+		}
+	}
+	return result, nil
+}
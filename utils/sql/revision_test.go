@@ -0,0 +1,68 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	pg "movies/utils/pg"
+	principal "movies/utils/principal"
+
+	pgtype "github.com/jackc/pgtype"
+)
+
+type revisionedModel struct {
+	Extended
+	Revisioned
+}
+
+func (revisionedModel) TableName() string { return "revisioned" }
+
+func TestRevisionActor(t *testing.T) {
+	moderator, archivist, editor := pg.NewUUID(), pg.NewUUID(), pg.NewUUID()
+	withModerator := principal.With(context.Background(), principal.Principal{UserID: moderator})
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		record Record
+		actor  pgtype.UUID
+	}{{
+		name:   "soft delete",
+		ctx:    withModerator,
+		record: softDeleteRecord(withModerator, &revisionedModel{}),
+		actor:  moderator,
+	}, {
+		name:   "archive",
+		ctx:    withModerator,
+		record: Record{"archived_at": NOW, "archived_by": archivist},
+		actor:  archivist,
+	}, {
+		name:   "archive without archiver",
+		ctx:    withModerator,
+		record: Record{"archived_at": NOW, "archived_by": pg.NullUUID()},
+		actor:  moderator,
+	}, {
+		name:   "update",
+		ctx:    withModerator,
+		record: Record{"title": "Alien", "updated_by": editor},
+		actor:  editor,
+	}, {
+		name:   "restore",
+		ctx:    withModerator,
+		record: Record{"deleted_at": nil, "deleted_by": nil},
+		actor:  moderator,
+	}, {
+		name:   "anonymous",
+		ctx:    context.Background(),
+		record: softDeleteRecord(context.Background(), &revisionedModel{}),
+		actor:  pg.NullUUID(),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actor := revisionActor(test.ctx, test.record); actor != test.actor {
+				t.Errorf("actor %v, expected %v", actor, test.actor)
+			}
+		})
+	}
+}
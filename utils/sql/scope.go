@@ -0,0 +1,97 @@
+package sql
+
+import (
+	"context"
+
+	exp "github.com/doug-martin/goqu/v9/exp"
+	lo "github.com/samber/lo"
+)
+
+/*============================================================================*/
+/*=====*                             Scopes                             *=====*/
+/*============================================================================*/
+
+// Default scopes of soft-deletable and archivable models
+const (
+	ScopeNotDeleted  = "not_deleted"
+	ScopeNotArchived = "not_archived"
+)
+
+// Scope: Condition applied to every read of a model unless lifted, nil when
+// it doesn't apply to the context (e.g. no tenant in a background job)
+type Scope struct {
+	Name  string
+	Where func(ctx context.Context, table string) exp.Expression
+}
+
+// Scoped: Model declaring default scopes on top of the soft-delete and
+// archive ones
+type Scoped interface {
+	Scopes() []Scope
+}
+
+// Scopes: Default scopes of a model
+func Scopes[M any]() []Scope {
+	var scopes []Scope
+	if _, ok := any(*new(M)).(interface{ GetDeleted() Deleted }); ok {
+		scopes = append(scopes, Scope{Name: ScopeNotDeleted, Where: isNull("deleted_at")})
+	}
+	if _, ok := any(*new(M)).(interface{ GetArchived() Archived }); ok {
+		scopes = append(scopes, Scope{Name: ScopeNotArchived, Where: isNull("archived_at")})
+	}
+	if model, ok := any(*new(M)).(Scoped); ok {
+		scopes = append(scopes, model.Scopes()...)
+	}
+	return scopes
+}
+
+// WithDeleted: Include soft-deleted rows
+func (d *readQuery[M]) WithDeleted() *readQuery[M] {
+	return d.Unscoped(ScopeNotDeleted)
+}
+
+// WithArchived: Include archived rows
+func (d *readQuery[M]) WithArchived() *readQuery[M] {
+	return d.Unscoped(ScopeNotArchived)
+}
+
+// OnlyDeleted: Only soft-deleted rows
+func (d *readQuery[M]) OnlyDeleted() *readQuery[M] {
+	return d.Unscoped(ScopeNotDeleted).Where(T(d.table).Col("deleted_at").IsNotNull())
+}
+
+// Unscoped: Lift the given default scopes, or all of them when none is given
+func (d *readQuery[M]) Unscoped(names ...string) *readQuery[M] {
+	if len(names) == 0 {
+		d.unscoped = true
+	}
+	d.lifted = append(d.lifted, names...)
+	return d
+}
+
+/*============================================================================*/
+/*=====*                            Internal                            *=====*/
+/*============================================================================*/
+
+// scope: Conditions of the default scopes still applying to a read
+func (d readQuery[M]) scope(ctx context.Context) []exp.Expression {
+	if d.unscoped {
+		return nil
+	}
+	var conditions []exp.Expression
+	for _, scope := range Scopes[M]() {
+		if lo.Contains(d.lifted, scope.Name) {
+			continue
+		}
+		if condition := scope.Where(ctx, d.table); condition != nil {
+			conditions = append(conditions, condition)
+		}
+	}
+	return conditions
+}
+
+func isNull(column string) func(context.Context, string) exp.Expression {
+	return func(_ context.Context, table string) exp.Expression {
+		return T(table).Col(column).IsNull()
+	}
+}
@@ -14,12 +14,28 @@ import (
 )
 
 func Read[M Table]() *readQuery[M] {
-	return &readQuery[M]{dataset: pg.SQLBuilder().From((*new(M)).TableName())}
+	table := (*new(M)).TableName()
+	return &readQuery[M]{dataset: pg.SQLBuilder().From(table), table: table}
 }
 
 type readQuery[M any] struct {
 	empty   bool
 	dataset *goqu.SelectDataset
+
+	// Name or alias of the model table
+	table string
+	// Default scopes lifted by name
+	lifted []string
+	// All default scopes lifted
+	unscoped bool
+}
+
+// query: Dataset with the default scopes of the model applied
+func (d readQuery[M]) query(ctx context.Context) *goqu.SelectDataset {
+	if conditions := d.scope(ctx); len(conditions) > 0 {
+		return d.dataset.Where(conditions...)
+	}
+	return d.dataset
 }
 
 func (d *readQuery[M]) append(clause *goqu.SelectDataset) *readQuery[M] {
@@ -32,7 +48,7 @@ func (d readQuery[M]) Count(ctx context.Context, tx pg.Tx) (int, error) {
 		return 0, nil
 	}
 
-	sql, args, err := d.dataset.ToSQL()
+	sql, args, err := d.query(ctx).ToSQL()
 	if err != nil {
 		return 0, err
 	}
@@ -45,7 +61,7 @@ func (d readQuery[M]) FindOne(ctx context.Context, tx pg.Tx) (*M, error) {
 		return nil, pgx.ErrNoRows
 	}
 
-	sql, args, err := d.dataset.ToSQL()
+	sql, args, err := d.query(ctx).ToSQL()
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +74,7 @@ func (d readQuery[M]) FindAll(ctx context.Context, tx pg.Tx) ([]*M, error) {
 		return nil, nil
 	}
 
-	sql, args, err := d.dataset.ToSQL()
+	sql, args, err := d.query(ctx).ToSQL()
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +87,7 @@ func (d readQuery[M]) Get(ctx context.Context, tx pg.Tx, dst any) error {
 		return pgx.ErrNoRows
 	}
 
-	sql, args, err := d.dataset.ToSQL()
+	sql, args, err := d.query(ctx).ToSQL()
 	if err != nil {
 		return err
 	}
@@ -83,7 +99,7 @@ func (d readQuery[M]) Sel(ctx context.Context, tx pg.Tx, dst any) error {
 		return pgx.ErrNoRows
 	}
 
-	sql, args, err := d.dataset.ToSQL()
+	sql, args, err := d.query(ctx).ToSQL()
 	if err != nil {
 		return err
 	}
@@ -107,6 +123,11 @@ func (d *readQuery[M]) Limit(limit uint) *readQuery[M] {
 	return d.append(d.dataset.Limit(limit))
 }
 
+// ForUpdate: Lock selected rows until the end of the transaction
+func (d *readQuery[M]) ForUpdate() *readQuery[M] {
+	return d.append(d.dataset.ForUpdate(exp.Wait))
+}
+
 func (d *readQuery[M]) GroupBy(groupBy ...any) *readQuery[M] {
 	return d.append(d.dataset.GroupBy(groupBy...))
 }
@@ -128,6 +149,9 @@ func (d *readQuery[M]) From(table ...any) *readQuery[M] {
 }
 
 func (d *readQuery[M]) FromModel(model Table, as string) *readQuery[M] {
+	if model.TableName() == any(*new(M)).(Table).TableName() {
+		d.table = as
+	}
 	return d.append(d.dataset.From(TAs(model, as)))
 }
 
@@ -154,3 +178,9 @@ func (d *readQuery[M]) Having(expressions ...exp.Expression) *readQuery[M] {
 func (d *readQuery[M]) Raw() *goqu.SelectDataset {
 	return d.dataset
 }
+
+// Scoped: Dataset with the default scopes of the model applied, to embed it
+// as a subquery
+func (d *readQuery[M]) Scoped(ctx context.Context) *goqu.SelectDataset {
+	return d.query(ctx)
+}
@@ -0,0 +1,160 @@
+package sql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	cerrors "movies/utils/cerrors"
+	form "movies/utils/form"
+
+	lo "github.com/samber/lo"
+)
+
+/*============================================================================*/
+/*=====*                          Setting Key                           *=====*/
+/*============================================================================*/
+
+// SettingKey: Typed setting with a default value and a validation tag
+type SettingKey[T any] struct {
+	Name    string
+	Default T
+	// Validation tag, as in a `validate` struct tag
+	Validate string
+}
+
+// Get: Read the setting, the default is returned when it is not set
+func (k SettingKey[T]) Get(obj Setting) (T, error) {
+	value, err := obj.GetSetting(k.Name, nil)
+	if err != nil || value == nil {
+		return k.Default, err
+	}
+	return k.decode(value)
+}
+
+// Set: Validate and write the setting, nil resets it to its default
+func (k SettingKey[T]) Set(obj *Setting, value *T) error {
+	if value == nil {
+		return obj.SetSetting(k.Name, nil)
+	}
+	if err := k.validate(*value); err != nil {
+		return err
+	}
+	return obj.SetSetting(k.Name, *value)
+}
+
+func (k SettingKey[T]) name() string { return k.Name }
+
+func (k SettingKey[T]) get(obj Setting) (any, error) { return k.Get(obj) }
+
+func (k SettingKey[T]) patch(obj *Setting, raw json.RawMessage) error {
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return k.Set(obj, nil)
+	}
+	value, err := k.decode(raw)
+	if err != nil {
+		return err
+	}
+	return k.Set(obj, &value)
+}
+
+// decode: Convert a JSON value to the setting type
+func (k SettingKey[T]) decode(value any) (T, error) {
+	raw, ok := value.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(value); err != nil {
+			return k.Default, err
+		}
+	}
+
+	var result T
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return k.Default, cerrors.NewValidation(
+			"type", k.Name,
+			fmt.Sprintf("`%s` must be of type %T", k.Name, k.Default),
+			string(raw),
+		)
+	}
+	return result, nil
+}
+
+func (k SettingKey[T]) validate(value T) error {
+	if k.Validate == "" {
+		return nil
+	}
+	return form.ValidateVar(k.Name, value, k.Validate)
+}
+
+/*============================================================================*/
+/*=====*                        Setting Registry                        *=====*/
+/*============================================================================*/
+
+type settingKey interface {
+	name() string
+	get(obj Setting) (any, error)
+	patch(obj *Setting, raw json.RawMessage) error
+}
+
+// SettingRegistry: Known settings of a model
+type SettingRegistry struct {
+	keys map[string]settingKey
+}
+
+func NewSettingRegistry() *SettingRegistry {
+	return &SettingRegistry{keys: map[string]settingKey{}}
+}
+
+// RegisterSetting: Declare a typed setting in a registry
+func RegisterSetting[T any](registry *SettingRegistry, name string, empty T, validate string) SettingKey[T] {
+	if _, ok := registry.keys[name]; ok {
+		panic(fmt.Sprintf("setting `%s` already registered", name))
+	}
+	key := SettingKey[T]{Name: name, Default: empty, Validate: validate}
+	registry.keys[name] = key
+	return key
+}
+
+// Values: Every registered setting, with defaults for unset ones
+func (r *SettingRegistry) Values(obj Setting) (map[string]any, error) {
+	values := make(map[string]any, len(r.keys))
+	for name, key := range r.keys {
+		value, err := key.get(obj)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// Patch: Apply a partial update, null values reset settings to their default
+//
+// Unknown keys and invalid values are reported as validation errors.
+func (r *SettingRegistry) Patch(obj *Setting, values map[string]json.RawMessage) error {
+	names := lo.Keys(values)
+	sort.Strings(names)
+
+	var er *cerrors.Error
+	for _, name := range names {
+		key, ok := r.keys[name]
+		if !ok {
+			er = er.Append(cerrors.NewValidation("unknown", name, fmt.Sprintf("`%s` is not a setting", name), name))
+			continue
+		}
+		if err := key.patch(obj, values[name]); err != nil {
+			cerr := cerrors.IsError(err)
+			if cerr == nil {
+				return err
+			}
+			er = er.Append(cerr)
+		}
+	}
+	if er != nil {
+		return er
+	}
+	return nil
+}
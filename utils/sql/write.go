@@ -3,7 +3,9 @@ package sql
 import (
 	"context"
 
+	form "movies/utils/form"
 	pg "movies/utils/pg"
+	principal "movies/utils/principal"
 
 	goqu "github.com/doug-martin/goqu/v9"
 	exp "github.com/doug-martin/goqu/v9/exp"
@@ -13,6 +15,9 @@ import (
 func Create[
 	M interface{ TableName() string },
 ](ctx context.Context, tx pg.Tx, data M, record Record) error {
+	if _, ok := any(data).(interface{ SetCreatedID(pgtype.UUID) }); ok {
+		setActor(ctx, record, "created_by")
+	}
 	sql, args, err := pg.SQLBuilder().
 		Insert(data.TableName()).
 		Rows(record).
@@ -21,7 +26,7 @@ func Create[
 	if err != nil {
 		return err
 	}
-	return pg.Get(ctx, tx, data, sql, args...)
+	return mapConstraint(data, pg.Get(ctx, tx, data, sql, args...), record)
 }
 
 func Update[
@@ -29,8 +34,19 @@ func Update[
 ](ctx context.Context, tx pg.Tx, data M, update bool, record Record, expressions exp.Expression) error {
 	if update {
 		record["updated_at"] = NOW
+		if _, ok := any(data).(interface{ SetUpdatedID(pgtype.UUID) }); ok {
+			setActor(ctx, record, "updated_by")
+		}
+	}
+	if model, ok := any(data).(Revisionable); ok {
+		return mapConstraint(data, updateRevisioned(ctx, tx, model, record, expressions), record)
 	}
+	return mapConstraint(data, updateRow(ctx, tx, data, record, expressions), record)
+}
 
+func updateRow[
+	M interface{ TableName() string },
+](ctx context.Context, tx pg.Tx, data M, record Record, expressions exp.Expression) error {
 	sql, args, err := pg.SQLBuilder().
 		Update(data.TableName()).
 		Set(record).
@@ -62,13 +78,70 @@ func SoftDeleteByPK[
 	},
 ](ctx context.Context, tx pg.Tx, data M) error {
 	return Update(ctx, tx, data, false,
-		Record{"deleted_at": "NOW"},
+		softDeleteRecord(ctx, data),
 		goqu.And(
 			goqu.I("id").Eq(data.GetPK()),
 			goqu.I("deleted_at").IsNull(),
 		))
 }
 
+func RestoreByPK[
+	M interface {
+		GetPK() pgtype.UUID
+		GetDeleted() Deleted
+		TableName() string
+	},
+](ctx context.Context, tx pg.Tx, data M) error {
+	return Update(ctx, tx, data, true,
+		Record{"deleted_at": nil, "deleted_by": nil},
+		goqu.And(
+			goqu.I("id").Eq(data.GetPK()),
+			goqu.I("deleted_at").IsNotNull(),
+		))
+}
+
+// ArchiveByPK: Archive a row, archived_by is taken from the model or else from
+// the principal of the context
+func ArchiveByPK[
+	M interface {
+		GetPK() pgtype.UUID
+		GetArchived() Archived
+		TableName() string
+	},
+](ctx context.Context, tx pg.Tx, data M) error {
+	record := Record{"archived_at": NOW}
+	if archivedBy := data.GetArchived().ArchivedBy; archivedBy.Status == pgtype.Present {
+		record["archived_by"] = archivedBy
+	} else if !setActor(ctx, record, "archived_by") {
+		form.RemoveUndefined(&archivedBy.Status)
+		record["archived_by"] = archivedBy
+	}
+
+	return Update(ctx, tx, data, false,
+		record,
+		goqu.And(
+			goqu.I("id").Eq(data.GetPK()),
+			goqu.I("archived_at").IsNull(),
+			goqu.I("deleted_at").IsNull(),
+		))
+}
+
+func UnarchiveByPK[
+	M interface {
+		GetPK() pgtype.UUID
+		GetArchived() Archived
+		TableName() string
+	},
+](ctx context.Context, tx pg.Tx, data M) error {
+	return Update(ctx, tx, data, true,
+		Record{"archived_at": nil, "archived_by": nil},
+		goqu.And(
+			goqu.I("id").Eq(data.GetPK()),
+			goqu.I("archived_at").IsNotNull(),
+			goqu.I("deleted_at").IsNull(),
+		))
+}
+
 func HardDelete[
 	M interface{ TableName() string },
 ](ctx context.Context, tx pg.Tx, data M, expressions exp.Expression) (int64, error) {
@@ -82,3 +155,27 @@ func HardDelete[
 	ct, err := pg.Client(tx).Exec(ctx, sql, args...)
 	return ct.RowsAffected(), err
 }
+
+// softDeleteRecord: Columns set by a soft delete, deleted_by from the principal
+// of the context
+func softDeleteRecord(ctx context.Context, data any) Record {
+	record := Record{"deleted_at": "NOW"}
+	if _, ok := data.(interface{ SetDeletedID(pgtype.UUID) }); ok {
+		setActor(ctx, record, "deleted_by")
+	}
+	return record
+}
+
+// setActor: Fill an actor column from the principal of the context, unless the
+// record already sets it
+func setActor(ctx context.Context, record Record, column string) bool {
+	if _, ok := record[column]; ok {
+		return true
+	}
+	p, ok := principal.FromContext(ctx)
+	if !ok || p.UserID.Status != pgtype.Present {
+		return false
+	}
+	record[column] = p.UserID
+	return true
+}